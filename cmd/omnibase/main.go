@@ -1,23 +1,38 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"omnibase/internal/admission"
+	"omnibase/internal/analytics"
+	"omnibase/internal/auth"
+	"omnibase/internal/breaker"
 	"omnibase/internal/config"
 	"omnibase/internal/flow"
 	"omnibase/internal/httpapi"
+	"omnibase/internal/jobs"
 	"omnibase/internal/llm"
 	"omnibase/internal/logging"
 	"omnibase/internal/mcp"
+	"omnibase/internal/metrics"
 	"omnibase/internal/qdrant"
+	"omnibase/internal/ratelimit"
+	"omnibase/internal/retrieval"
+	"omnibase/internal/schema"
+	"omnibase/internal/tracing"
 )
 
+const ragTopK = 5
+
 func main() {
 	logger := logging.NewLogger()
 	cfg, err := config.Load()
@@ -26,8 +41,80 @@ func main() {
 		os.Exit(1)
 	}
 
-	qdrantClient := qdrant.NewClient(cfg.QdrantURL, cfg.QdrantAPIKey, cfg.QdrantCollection)
-	llmClient := llm.NewClient(cfg.LLMBaseURL, cfg.LLMModel)
+	appMetrics := metrics.New()
+
+	var exporters tracing.Exporters
+	if cfg.TraceExportEndpoint != "" {
+		exporters = append(exporters, tracing.NewHTTPExporter(cfg.ServiceName, cfg.TraceExportEndpoint))
+	}
+	if cfg.TraceExportFile != "" {
+		exporters = append(exporters, tracing.NewFileExporter(cfg.ServiceName, cfg.TraceExportFile))
+	}
+	var exporter tracing.Exporter
+	if len(exporters) > 0 {
+		exporter = exporters
+	}
+	tracer := tracing.NewTracer(tracing.TracerConfig{ServiceName: cfg.ServiceName}, exporter, logger)
+
+	breakerConfig := breaker.Config{
+		FailureThreshold: cfg.BreakerFailureThreshold,
+		OpenTimeout:      cfg.BreakerOpenTimeout,
+		HalfOpenMaxCalls: cfg.BreakerHalfOpenMaxCalls,
+		OnStateChange: func(name string, from, to breaker.State) {
+			logger.Warn("circuit breaker state changed", "dependency", name, "from", from.String(), "to", to.String())
+		},
+	}
+	llmBreaker := breaker.New("llm", breakerConfig)
+	qdrantBreaker := breaker.New("qdrant", breakerConfig)
+	mcpBreaker := breaker.New("mcp", breakerConfig)
+	embeddingBreaker := breaker.New("embedding", breakerConfig)
+
+	qdrantClient := qdrant.NewClient(cfg.QdrantURL, cfg.QdrantAPIKey, cfg.QdrantCollection).WithBreaker(qdrantBreaker).WithMetrics(appMetrics)
+	qdrantClient.WithVectorNames(cfg.QdrantDenseName, cfg.QdrantSparseName)
+	if cfg.QdrantOptional != "" {
+		qdrantClient.WithOptionalFilterFields(strings.Split(cfg.QdrantOptional, ",")...)
+	}
+	llmLimiter := admission.New("llm", admission.Config{
+		MaxConcurrent: int64(cfg.LLMMaxConcurrency),
+		MaxQueue:      cfg.LLMMaxQueue,
+		QueueTimeout:  cfg.LLMQueueTimeout,
+	})
+	llmClient := llm.NewClient(cfg.LLMBaseURL, cfg.LLMModel).WithBreaker(llmBreaker).WithLimiter(llmLimiter).WithMetrics(appMetrics)
+	embeddingClient := llm.NewClient(cfg.EmbeddingBaseURL, cfg.EmbeddingModel).WithAPIKey(cfg.EmbeddingAPIKey).
+		WithDimensions(cfg.EmbeddingDimensions).WithBatching(cfg.EmbeddingBatchSize, cfg.EmbeddingMaxTokens, cfg.EmbeddingParallel).
+		WithBreaker(embeddingBreaker).WithMetrics(appMetrics)
+	checkCtx, cancelCheck := context.WithTimeout(context.Background(), 10*time.Second)
+	size, sizeErr := qdrantClient.VectorSize(checkCtx)
+	dimensions, probeErr := embeddingDimensions(checkCtx, embeddingClient)
+	if probeErr != nil {
+		dimensions = cfg.EmbeddingDimensions
+	}
+	switch {
+	case probeErr == nil && cfg.EmbeddingDimensions > 0 && dimensions != cfg.EmbeddingDimensions:
+		logger.Error("embedding dimensions do not match configuration", "embedding_dimensions", dimensions, "configured_dimensions", cfg.EmbeddingDimensions)
+		os.Exit(1)
+	case sizeErr == nil && dimensions > 0 && dimensions != size:
+		logger.Error("embedding dimensions do not match qdrant collection", "embedding_dimensions", dimensions, "collection_vector_size", size)
+		os.Exit(1)
+	}
+	for _, err := range []error{sizeErr, probeErr} {
+		if err == nil {
+			continue
+		}
+		if cfg.StrictStartup {
+			logger.Error("embedding dimension check failed", "error", err.Error())
+			os.Exit(1)
+		}
+		logger.Warn("embedding dimension check incomplete", "error", err.Error())
+	}
+	if qdrantClient.SparseEnabled() {
+		if modifier, err := qdrantClient.SparseModifier(checkCtx); err != nil {
+			logger.Warn("sparse vector check skipped", "error", err.Error())
+		} else if modifier != qdrant.ModifierIDF {
+			logger.Warn("sparse vector has no idf modifier, keyword scores ignore term rarity", "vector", cfg.QdrantSparseName, "modifier", modifier)
+		}
+	}
+	cancelCheck()
 	toolRegistry := mcp.DefaultTools()
 	var sqlExecutor *mcp.SQLExecutor
 	if cfg.MySQLDSN != "" {
@@ -47,18 +134,176 @@ func main() {
 		logger.Error("mcp client init failed", "error", err.Error())
 		os.Exit(1)
 	}
+	mcpClient.WithBreaker(mcpBreaker).WithMetrics(appMetrics)
+	breakers := []*breaker.Breaker{llmBreaker, qdrantBreaker, mcpBreaker, embeddingBreaker}
+	var rewriteNode *flow.QueryRewriteNode
+	if cfg.QueryRewrite {
+		rewriteNode = &flow.QueryRewriteNode{Client: llmClient, Variants: cfg.QueryVariants, HyDE: cfg.QueryHyDE, Metrics: appMetrics}
+	}
+	var rerankNode *flow.RerankNode
+	switch cfg.RerankBackend {
+	case flow.RerankEndpoint:
+		rerankBreaker := breaker.New("rerank", breakerConfig)
+		breakers = append(breakers, rerankBreaker)
+		rerankClient := llm.NewClient(cmp.Or(cfg.RerankBaseURL, cfg.LLMBaseURL), cmp.Or(cfg.RerankModel, cfg.LLMModel)).WithBreaker(rerankBreaker)
+		rerankNode = &flow.RerankNode{Backend: cfg.RerankBackend, Client: rerankClient, TopK: ragTopK, Metrics: appMetrics}
+	case flow.RerankLLM:
+		rerankNode = &flow.RerankNode{Backend: cfg.RerankBackend, Client: llmClient, TopK: ragTopK, BatchSize: cfg.RerankBatchSize, Metrics: appMetrics}
+	}
+	appMetrics.RegisterBreakers(breakers...)
+	appMetrics.RegisterLimiters(llmLimiter)
+
+	thresholds := schema.ScoreThresholds{
+		Pass:     cfg.ScorePassThreshold,
+		Strength: cfg.ScoreStrengthThreshold,
+		Weakness: cfg.ScoreWeaknessThreshold,
+	}
+	if err := analytics.ValidateThresholds(thresholds); err != nil {
+		logger.Error("score threshold config invalid", "error", err.Error())
+		os.Exit(1)
+	}
+
+	keywordWeights, err := retrieval.ParseWeights(cfg.RAGKeywordWeight)
+	if err != nil {
+		logger.Error("rag keyword weight config invalid", "error", err.Error())
+		os.Exit(1)
+	}
 
+	ragNode := flow.RAGRetrievalNode{
+		Embedder:       embeddingClient,
+		Qdrant:         qdrantClient,
+		TopK:           ragTopK,
+		MinScore:       cfg.RAGMinScore,
+		KeywordWeights: keywordWeights,
+		Metrics:        appMetrics,
+	}
+	var diversity retrieval.Diversity
+	if cfg.RAGMMRLambda < 1 || cfg.RAGMaxPerDoc > 0 {
+		diversity = retrieval.Diversity{Lambda: cfg.RAGMMRLambda, Candidates: cfg.RAGMMRCandidates, MaxPerDocument: cfg.RAGMaxPerDoc}
+	}
+	if rerankNode != nil {
+		ragNode.TopK = cfg.RerankCandidates
+		ragNode.WithVectors = diversity.Enabled()
+		rerankNode.Diversity = diversity
+	} else {
+		ragNode.Diversity = diversity
+	}
+	mcpNode := flow.MCPToolDispatchNode{Client: mcpClient, Policy: auth.ArgumentPolicy{Classes: mcpClient}, Metrics: appMetrics}
+	llmNode := flow.LLMCompletionNode{
+		Client:        llmClient,
+		Tools:         toolRegistry,
+		Dispatcher:    mcpNode,
+		MaxToolRounds: cfg.LLMMaxToolRounds,
+		NoContext:     cfg.RAGNoContext,
+		Metrics:       appMetrics,
+	}
 	pipeline := flow.Flow{
-		Normalizer: flow.RequestNormalizerNode{},
-		RAG:        flow.RAGRetrievalNode{LLM: llmClient, Qdrant: qdrantClient, TopK: 5},
-		MCP:        flow.MCPToolDispatchNode{Client: mcpClient},
-		LLM:        flow.LLMCompletionNode{Client: llmClient, Tools: toolRegistry},
-		Formatter:  flow.ResponseFormatterNode{},
+		Normalizer:   flow.RequestNormalizerNode{},
+		Rewrite:      rewriteNode,
+		RAG:          ragNode,
+		Rerank:       rerankNode,
+		MCP:          mcpNode,
+		Analytics:    flow.ScoreAnalyticsNode{Thresholds: thresholds},
+		LLM:          llmNode,
+		Formatter:    flow.ResponseFormatterNode{Metrics: appMetrics},
+		Interceptors: flow.DefaultInterceptors(logger, appMetrics),
+	}
+
+	apiKeys, err := auth.ParseKeySpec(cfg.APIKeys)
+	if err != nil {
+		logger.Error("api key config invalid", "error", err.Error())
+		os.Exit(1)
 	}
+	if cfg.APIKeysFile != "" {
+		fileKeys, err := auth.LoadKeyFile(cfg.APIKeysFile)
+		if err != nil {
+			logger.Error("api key file load failed", "error", err.Error())
+			os.Exit(1)
+		}
+		apiKeys = append(apiKeys, fileKeys...)
+	}
+	keyStore, err := auth.NewKeyStore(apiKeys)
+	if err != nil {
+		logger.Error("api key store init failed", "error", err.Error())
+		os.Exit(1)
+	}
+	var jwtVerifier *auth.JWTVerifier
+	if cfg.JWTJWKSFile != "" {
+		jwks, err := auth.LoadJWKSFile(cfg.JWTJWKSFile)
+		if err != nil {
+			logger.Error("jwks load failed", "error", err.Error())
+			os.Exit(1)
+		}
+		jwtVerifier, err = auth.NewJWTVerifier(jwks, auth.JWTConfig{Issuer: cfg.JWTIssuer, Audience: cfg.JWTAudience})
+		if err != nil {
+			logger.Error("jwt verifier init failed", "error", err.Error())
+			os.Exit(1)
+		}
+	}
+	authenticator := httpapi.NewAuthenticator(keyStore, jwtVerifier, logger)
+	if !authenticator.Enabled() {
+		logger.Warn("no API keys configured, HTTP API is unauthenticated")
+	}
+
+	rateLimitPolicy, err := ratelimit.ParsePolicy(cfg.RateLimits)
+	if err != nil {
+		logger.Error("rate limit config invalid", "error", err.Error())
+		os.Exit(1)
+	}
+	rateLimitPolicy.Limiter = ratelimit.NewLimiter(cfg.RateLimitIdleTTL)
+	rateLimiter := httpapi.NewRateLimiter(&rateLimitPolicy, logger)
+
+	mux := http.NewServeMux()
+	health := httpapi.NewHealthHandler(breakers, []*admission.Limiter{llmLimiter})
+	mux.Handle("/healthz", health)
+	mux.HandleFunc("/readyz", health.ServeReady)
+	handler := httpapi.NewHandler(pipeline, logger)
+	handler.Tracer = tracer
+	handler.Metrics = appMetrics
+	handler.RateLimits = &rateLimitPolicy
+	handler.MaxBodyBytes = int64(cfg.HTTPMaxBodyBytes)
+	handler.BatchParallelism = cfg.BatchParallelism
+	mux.Handle("/metrics", appMetrics.Registry.Handler())
+	mux.Handle("/", authenticator.Middleware(rateLimiter.Middleware(handler)))
+	mux.Handle("POST /v1/batch/student-analysis", authenticator.Middleware(rateLimiter.Middleware(http.HandlerFunc(handler.ServeBatch))))
+
+	var jobStore jobs.Store = jobs.NewMemoryStore()
+	if cfg.JobsStoreDSN != "" {
+		sqlStore, err := jobs.NewSQLStore(context.Background(), cfg.JobsStoreDriver, cfg.JobsStoreDSN)
+		if err != nil {
+			logger.Error("job store init failed", "error", err.Error())
+			os.Exit(1)
+		}
+		defer func() {
+			if err := sqlStore.Close(); err != nil {
+				logger.Error("job store close failed", "error", err.Error())
+			}
+		}()
+		jobStore = sqlStore
+	}
+	var webhookHosts []string
+	if cfg.WebhookAllowedHosts != "" {
+		webhookHosts = strings.Split(cfg.WebhookAllowedHosts, ",")
+	}
+	notifier := jobs.NewNotifier(cfg.WebhookSecret, webhookHosts)
+	jobManager := jobs.NewManager(jobs.Config{
+		Workers:   cfg.JobsWorkers,
+		QueueSize: cfg.JobsQueueSize,
+		Timeout:   cfg.JobsTimeout,
+		Retention: cfg.JobsRetention,
+	}, jobStore, handler.ExecuteJob, notifier, logger.With(slog.String(logging.KeyComponent, "jobs")))
+	if err := jobManager.Start(context.Background()); err != nil {
+		logger.Error("job manager start failed", "error", err.Error())
+		os.Exit(1)
+	}
+	jobsHandler := httpapi.NewJobsHandler(handler, jobManager, notifier)
+	mux.Handle("POST /v1/jobs", authenticator.Middleware(rateLimiter.Middleware(http.HandlerFunc(jobsHandler.Create))))
+	mux.Handle("GET /v1/jobs/{id}", authenticator.Middleware(rateLimiter.Middleware(http.HandlerFunc(jobsHandler.Get))))
+	mux.Handle("DELETE /v1/jobs/{id}", authenticator.Middleware(rateLimiter.Middleware(http.HandlerFunc(jobsHandler.Cancel))))
 
 	server := &http.Server{
 		Addr:         cfg.HTTPAddr,
-		Handler:      httpapi.NewHandler(pipeline, logger),
+		Handler:      mux,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 60 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -69,6 +314,8 @@ func main() {
 	shutdownCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer stop()
 
+	go rateLimitPolicy.Limiter.RunEviction(shutdownCtx, time.Minute)
+
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Error("server failed", "error", err.Error())
@@ -84,4 +331,19 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Error("server shutdown failed", "error", err.Error())
 	}
+	if err := jobManager.Shutdown(ctx); err != nil {
+		logger.Error("job manager shutdown failed", "error", err.Error())
+	}
+	if err := tracer.Shutdown(ctx); err != nil {
+		logger.Error("tracer shutdown failed", "error", err.Error())
+	}
+}
+
+func embeddingDimensions(ctx context.Context, client *llm.Client) (int, error) {
+	vector, err := client.Embed(ctx, "dimension check")
+	var dimensionErr *llm.DimensionError
+	if errors.As(err, &dimensionErr) {
+		return dimensionErr.Got, nil
+	}
+	return len(vector), err
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"omnibase/internal/retrieval"
+)
+
+type document struct {
+	ID   json.RawMessage `json:"id"`
+	Text string          `json:"text"`
+}
+
+type sparseVector struct {
+	Indices []uint32  `json:"indices"`
+	Values  []float32 `json:"values"`
+}
+
+type point struct {
+	ID     json.RawMessage         `json:"id"`
+	Vector map[string]sparseVector `json:"vector"`
+}
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	name := os.Getenv("OMNIBASE_QDRANT_SPARSE_VECTOR")
+	if name == "" {
+		logger.Error("OMNIBASE_QDRANT_SPARSE_VECTOR is required")
+		os.Exit(1)
+	}
+
+	var documents []document
+	var tokens int
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var doc document
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			logger.Error("invalid document line", "document", len(documents)+1, "error", err.Error())
+			os.Exit(1)
+		}
+		if len(doc.ID) == 0 {
+			logger.Error("document has no id", "document", len(documents)+1)
+			os.Exit(1)
+		}
+		documents = append(documents, doc)
+		tokens += len(retrieval.Tokenize(doc.Text))
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Error("read documents failed", "error", err.Error())
+		os.Exit(1)
+	}
+	if len(documents) == 0 {
+		return
+	}
+
+	averageLength := float64(tokens) / float64(len(documents))
+	out := bufio.NewWriter(os.Stdout)
+	encoder := json.NewEncoder(out)
+	for _, doc := range documents {
+		vector := retrieval.EncodeDocument(doc.Text, averageLength)
+		if err := encoder.Encode(point{ID: doc.ID, Vector: map[string]sparseVector{name: {Indices: vector.Indices, Values: vector.Values}}}); err != nil {
+			logger.Error("write point failed", "error", err.Error())
+			os.Exit(1)
+		}
+	}
+	if err := out.Flush(); err != nil {
+		logger.Error("write points failed", "error", err.Error())
+		os.Exit(1)
+	}
+	logger.Info("sparse vectors encoded", slog.Int("documents", len(documents)), slog.Float64("average_length", averageLength))
+}
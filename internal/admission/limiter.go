@@ -0,0 +1,165 @@
+package admission
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrSaturated = errors.New("capacity exhausted")
+
+const (
+	ReasonQueueFull    = "queue_full"
+	ReasonQueueTimeout = "queue_timeout"
+)
+
+type SaturatedError struct {
+	Name       string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *SaturatedError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Name, ErrSaturated, e.Reason)
+}
+
+func (e *SaturatedError) Is(target error) bool { return target == ErrSaturated }
+
+type Config struct {
+	MaxConcurrent int64
+	MaxQueue      int
+	QueueTimeout  time.Duration
+}
+
+type Stats struct {
+	Capacity int64  `json:"capacity"`
+	InFlight int64  `json:"in_flight"`
+	Queued   int    `json:"queued"`
+	Admitted uint64 `json:"admitted"`
+	Rejected uint64 `json:"rejected"`
+	TimedOut uint64 `json:"timed_out"`
+}
+
+type waiter struct {
+	weight int64
+	ready  chan struct{}
+}
+
+type Limiter struct {
+	name string
+	cfg  Config
+
+	mu       sync.Mutex
+	inFlight int64
+	waiters  list.List
+	admitted uint64
+	rejected uint64
+	timedOut uint64
+}
+
+func New(name string, cfg Config) *Limiter {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 1
+	}
+	if cfg.MaxQueue < 0 {
+		cfg.MaxQueue = 0
+	}
+	if cfg.QueueTimeout <= 0 {
+		cfg.QueueTimeout = 30 * time.Second
+	}
+	return &Limiter{name: name, cfg: cfg}
+}
+
+func (l *Limiter) Name() string { return l.name }
+
+func (l *Limiter) Acquire(ctx context.Context, weight int64) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+	if weight > l.cfg.MaxConcurrent {
+		weight = l.cfg.MaxConcurrent
+	}
+	release := func() { l.release(weight) }
+
+	l.mu.Lock()
+	if l.waiters.Len() == 0 && l.inFlight+weight <= l.cfg.MaxConcurrent {
+		l.inFlight += weight
+		l.admitted++
+		l.mu.Unlock()
+		return release, nil
+	}
+	if l.waiters.Len() >= l.cfg.MaxQueue {
+		l.rejected++
+		l.mu.Unlock()
+		return nil, &SaturatedError{Name: l.name, Reason: ReasonQueueFull, RetryAfter: l.cfg.QueueTimeout}
+	}
+	w := &waiter{weight: weight, ready: make(chan struct{})}
+	elem := l.waiters.PushBack(w)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.cfg.QueueTimeout)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-w.ready:
+		return release, nil
+	case <-timer.C:
+		err = &SaturatedError{Name: l.name, Reason: ReasonQueueTimeout, RetryAfter: l.cfg.QueueTimeout}
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	select {
+	case <-w.ready:
+		l.mu.Unlock()
+		l.release(weight)
+	default:
+		l.waiters.Remove(elem)
+		if errors.Is(err, ErrSaturated) {
+			l.timedOut++
+		}
+		l.admitWaiters()
+		l.mu.Unlock()
+	}
+	return nil, err
+}
+
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return Stats{
+		Capacity: l.cfg.MaxConcurrent,
+		InFlight: l.inFlight,
+		Queued:   l.waiters.Len(),
+		Admitted: l.admitted,
+		Rejected: l.rejected,
+		TimedOut: l.timedOut,
+	}
+}
+
+func (l *Limiter) release(weight int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight -= weight
+	l.admitWaiters()
+}
+
+func (l *Limiter) admitWaiters() {
+	for elem := l.waiters.Front(); elem != nil; elem = l.waiters.Front() {
+		w := elem.Value.(*waiter)
+		if l.inFlight+w.weight > l.cfg.MaxConcurrent {
+			return
+		}
+		l.inFlight += w.weight
+		l.admitted++
+		l.waiters.Remove(elem)
+		close(w.ready)
+	}
+}
@@ -0,0 +1,110 @@
+package admission
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func waitQueued(t *testing.T, l *Limiter, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for l.Stats().Queued != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", l.Stats().Queued, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLimiterRejections(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		cancel   bool
+		wantErr  error
+		reason   string
+		rejected uint64
+		timedOut uint64
+	}{
+		{"queue full", Config{MaxConcurrent: 1, MaxQueue: 0, QueueTimeout: time.Second}, false, ErrSaturated, ReasonQueueFull, 1, 0},
+		{"queue timeout", Config{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond}, false, ErrSaturated, ReasonQueueTimeout, 0, 1},
+		{"caller canceled", Config{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: time.Second}, true, context.Canceled, "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New("llm", tt.cfg)
+			release, err := l.Acquire(context.Background(), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer release()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.cancel {
+				time.AfterFunc(10*time.Millisecond, cancel)
+			}
+			defer cancel()
+			_, err = l.Acquire(ctx, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Acquire() error = %v, want %v", err, tt.wantErr)
+			}
+			var saturated *SaturatedError
+			if tt.reason != "" && (!errors.As(err, &saturated) || saturated.Reason != tt.reason || saturated.RetryAfter <= 0) {
+				t.Fatalf("Acquire() error = %#v, want reason %s", err, tt.reason)
+			}
+			stats := l.Stats()
+			if stats.InFlight != 1 || stats.Queued != 0 || stats.Admitted != 1 || stats.Rejected != tt.rejected || stats.TimedOut != tt.timedOut {
+				t.Errorf("stats = %+v", stats)
+			}
+		})
+	}
+}
+
+func TestLimiterAdmitsWaitersInOrder(t *testing.T) {
+	l := New("llm", Config{MaxConcurrent: 2, MaxQueue: 2, QueueTimeout: time.Second})
+	release, err := l.Acquire(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := make(chan int, 2)
+	for i := range 2 {
+		go func() {
+			next, err := l.Acquire(context.Background(), 2)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+			next()
+		}()
+		waitQueued(t, l, i+1)
+	}
+	release()
+	if first, second := <-order, <-order; first != 0 || second != 1 {
+		t.Fatalf("admission order = %d, %d", first, second)
+	}
+	if stats := l.Stats(); stats.InFlight != 0 || stats.Admitted != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestLimiterClampsWeightAndNilLimiter(t *testing.T) {
+	l := New("llm", Config{MaxConcurrent: 2})
+	release, err := l.Acquire(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("oversized weight rejected: %v", err)
+	}
+	if stats := l.Stats(); stats.InFlight != 2 {
+		t.Errorf("in flight = %d, want 2", stats.InFlight)
+	}
+	release()
+
+	var none *Limiter
+	release, err = none.Acquire(context.Background(), 1)
+	if err != nil || release == nil {
+		t.Fatalf("nil limiter Acquire() = %v", err)
+	}
+	release()
+}
@@ -0,0 +1,127 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"omnibase/internal/schema"
+)
+
+var DefaultThresholds = schema.ScoreThresholds{Pass: 60, Strength: 85, Weakness: 70}
+
+func ValidateThresholds(t schema.ScoreThresholds) error {
+	if t.Pass < 0 || t.Strength < 0 || t.Weakness < 0 {
+		return fmt.Errorf("score thresholds must not be negative")
+	}
+	if t.Weakness > t.Strength {
+		return fmt.Errorf("weakness threshold %.2f must not exceed strength threshold %.2f", t.Weakness, t.Strength)
+	}
+	return nil
+}
+
+func Profile(scores []ScorePoint, stats []schema.GroupStats, thresholds schema.ScoreThresholds) schema.ScoreProfile {
+	bySubject := make(map[string]float64, len(scores))
+	for _, point := range scores {
+		bySubject[point.Subject] = point.Score
+	}
+	comparisons := make(map[string][]schema.Comparison)
+	for _, group := range stats {
+		for _, subject := range group.Subjects {
+			comparisons[subject.Subject] = append(comparisons[subject.Subject], schema.Comparison{Scope: group.Scope, GroupID: group.GroupID, SubjectStats: subject})
+		}
+	}
+
+	profile := schema.ScoreProfile{
+		Thresholds: thresholds,
+		Subjects:   make([]schema.SubjectProfile, 0, len(bySubject)),
+		Strengths:  []string{},
+		Weaknesses: []string{},
+	}
+	var sum float64
+	for subject, score := range bySubject {
+		sum += score
+		subjectComparisons := comparisons[subject]
+		if subjectComparisons == nil {
+			subjectComparisons = []schema.Comparison{}
+		}
+		profile.Subjects = append(profile.Subjects, schema.SubjectProfile{
+			Subject:     subject,
+			Score:       score,
+			Passed:      score >= thresholds.Pass,
+			Level:       level(score, thresholds),
+			Comparisons: subjectComparisons,
+		})
+	}
+	if len(bySubject) > 0 {
+		profile.Mean = round(sum / float64(len(bySubject)))
+	}
+
+	sort.Slice(profile.Subjects, func(i, j int) bool {
+		if profile.Subjects[i].Score != profile.Subjects[j].Score {
+			return profile.Subjects[i].Score > profile.Subjects[j].Score
+		}
+		return profile.Subjects[i].Subject < profile.Subjects[j].Subject
+	})
+	for i := range profile.Subjects {
+		profile.Subjects[i].Rank = i + 1
+		if i > 0 && profile.Subjects[i].Score == profile.Subjects[i-1].Score {
+			profile.Subjects[i].Rank = profile.Subjects[i-1].Rank
+		}
+		switch profile.Subjects[i].Level {
+		case schema.LevelStrength:
+			profile.Strengths = append(profile.Strengths, profile.Subjects[i].Subject)
+		case schema.LevelWeakness:
+			profile.Weaknesses = append(profile.Weaknesses, profile.Subjects[i].Subject)
+		}
+	}
+	return profile
+}
+
+func level(score float64, thresholds schema.ScoreThresholds) string {
+	switch {
+	case score >= thresholds.Strength:
+		return schema.LevelStrength
+	case score < thresholds.Weakness:
+		return schema.LevelWeakness
+	default:
+		return schema.LevelAverage
+	}
+}
+
+func average(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+func percentileRank(values []float64, score float64) float64 {
+	var below, equal float64
+	for _, value := range values {
+		switch {
+		case value < score:
+			below++
+		case value == score:
+			equal++
+		}
+	}
+	return (below + equal/2) / float64(len(values)) * 100
+}
+
+func Snapshot(profile schema.ScoreProfile) schema.DataSnapshot {
+	var snapshot schema.DataSnapshot
+	for _, subject := range profile.Subjects {
+		score := int(math.Round(subject.Score))
+		switch subject.Subject {
+		case "math":
+			snapshot.Math = &score
+		case "english":
+			snapshot.English = &score
+		case "physics":
+			snapshot.Physics = &score
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,128 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"omnibase/internal/schema"
+)
+
+type StatRow struct {
+	GroupID   string
+	Subject   string
+	Score     float64
+	IsStudent bool
+}
+
+func StatRows(payload map[string]any) ([]StatRow, error) {
+	var rows []map[string]any
+	switch values := payload["rows"].(type) {
+	case []map[string]any:
+		rows = values
+	case []any:
+		rows = make([]map[string]any, 0, len(values))
+		for i, value := range values {
+			row, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("rows[%d] is not an object", i)
+			}
+			rows = append(rows, row)
+		}
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("rows has unexpected type %T", values)
+	}
+	statRows := make([]StatRow, 0, len(rows))
+	for i, row := range rows {
+		subject, _ := row["subject"].(string)
+		score, ok := schema.FloatValue(row["score"])
+		if strings.TrimSpace(subject) == "" || !ok {
+			return nil, fmt.Errorf("rows[%d] requires subject and numeric score", i)
+		}
+		isStudent := false
+		switch flag := row["is_student"].(type) {
+		case bool:
+			isStudent = flag
+		default:
+			value, _ := schema.IntValue(flag)
+			isStudent = value != 0
+		}
+		statRows = append(statRows, StatRow{
+			GroupID:   fmt.Sprint(row["group_id"]),
+			Subject:   strings.ToLower(strings.TrimSpace(subject)),
+			Score:     score,
+			IsStudent: isStudent,
+		})
+	}
+	return statRows, nil
+}
+
+func GroupStats(scope, term string, rows []StatRow) []schema.GroupStats {
+	type key struct{ group, subject string }
+	scores := make(map[key][]float64)
+	student := make(map[key]float64)
+	groups := make(map[string][]string)
+	for _, row := range rows {
+		k := key{row.GroupID, row.Subject}
+		if _, ok := scores[k]; !ok {
+			groups[row.GroupID] = append(groups[row.GroupID], row.Subject)
+		}
+		scores[k] = append(scores[k], row.Score)
+		if row.IsStudent {
+			student[k] = row.Score
+		}
+	}
+	groupIDs := make([]string, 0, len(groups))
+	for groupID := range groups {
+		groupIDs = append(groupIDs, groupID)
+	}
+	sort.Strings(groupIDs)
+
+	stats := make([]schema.GroupStats, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		subjects := groups[groupID]
+		sort.Strings(subjects)
+		group := schema.GroupStats{Scope: scope, GroupID: groupID, Term: term, Subjects: make([]schema.SubjectStats, 0, len(subjects))}
+		for _, subject := range subjects {
+			k := key{groupID, subject}
+			values := scores[k]
+			subjectStats := schema.SubjectStats{
+				Subject: subject,
+				Count:   len(values),
+				Mean:    round(average(values)),
+				Median:  round(median(values)),
+				StdDev:  round(stdDev(values)),
+			}
+			if score, ok := student[k]; ok {
+				percentile := round(percentileRank(values, score))
+				subjectStats.StudentScore = &score
+				subjectStats.Percentile = &percentile
+			}
+			group.Subjects = append(group.Subjects, subjectStats)
+		}
+		stats = append(stats, group)
+	}
+	return stats
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+func stdDev(values []float64) float64 {
+	mean := average(values)
+	var sum float64
+	for _, value := range values {
+		sum += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
@@ -0,0 +1,139 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"omnibase/internal/schema"
+)
+
+const StableSlope = 1.0
+
+type ScorePoint struct {
+	Term    string
+	Subject string
+	Score   float64
+}
+
+func ScorePoints(payload map[string]any) ([]ScorePoint, error) {
+	var rows []map[string]any
+	switch values := payload["scores"].(type) {
+	case []map[string]any:
+		rows = values
+	case []any:
+		rows = make([]map[string]any, 0, len(values))
+		for i, value := range values {
+			row, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("scores[%d] is not an object", i)
+			}
+			rows = append(rows, row)
+		}
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("scores has unexpected type %T", values)
+	}
+	points := make([]ScorePoint, 0, len(rows))
+	for i, row := range rows {
+		term, _ := row["term"].(string)
+		subject, _ := row["subject"].(string)
+		score, ok := schema.FloatValue(row["score"])
+		if strings.TrimSpace(subject) == "" || !ok {
+			return nil, fmt.Errorf("scores[%d] requires subject and numeric score", i)
+		}
+		points = append(points, ScorePoint{Term: strings.TrimSpace(term), Subject: strings.ToLower(strings.TrimSpace(subject)), Score: score})
+	}
+	return points, nil
+}
+
+func Trend(points []ScorePoint) schema.TrendData {
+	termIndex := make(map[string]int)
+	bySubject := make(map[string]map[string]float64)
+	for _, point := range points {
+		termIndex[point.Term] = 0
+		if bySubject[point.Subject] == nil {
+			bySubject[point.Subject] = make(map[string]float64)
+		}
+		bySubject[point.Subject][point.Term] = point.Score
+	}
+	terms := make([]string, 0, len(termIndex))
+	for term := range termIndex {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	for i, term := range terms {
+		termIndex[term] = i
+	}
+	subjects := make([]string, 0, len(bySubject))
+	for subject := range bySubject {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+
+	data := schema.TrendData{Terms: terms, Direction: schema.TrendInsufficient, Subjects: make([]schema.SubjectTrend, 0, len(subjects))}
+	var slopeSum float64
+	var slopeCount int
+	for _, subject := range subjects {
+		trend := schema.SubjectTrend{Subject: subject, Direction: schema.TrendInsufficient, Changes: []schema.TermChange{}}
+		var xs, ys []float64
+		for _, term := range terms {
+			score, ok := bySubject[subject][term]
+			if !ok {
+				continue
+			}
+			if n := len(trend.Scores); n > 0 {
+				previous := trend.Scores[n-1]
+				trend.Changes = append(trend.Changes, schema.TermChange{From: previous.Term, To: term, Change: round(score - previous.Score)})
+			}
+			trend.Scores = append(trend.Scores, schema.TermScore{Term: term, Score: score})
+			xs = append(xs, float64(termIndex[term]))
+			ys = append(ys, score)
+		}
+		if n := len(trend.Scores); n >= 2 {
+			trend.Delta = round(trend.Scores[n-1].Score - trend.Scores[0].Score)
+			trend.Slope = round(slope(xs, ys))
+			trend.Direction = direction(trend.Slope)
+			slopeSum += trend.Slope
+			slopeCount++
+		}
+		data.Subjects = append(data.Subjects, trend)
+	}
+	if data.Sufficient() && slopeCount > 0 {
+		data.Direction = direction(slopeSum / float64(slopeCount))
+	}
+	return data
+}
+
+func slope(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+func direction(slope float64) string {
+	switch {
+	case slope >= StableSlope:
+		return schema.TrendImproving
+	case slope <= -StableSlope:
+		return schema.TrendDeclining
+	default:
+		return schema.TrendStable
+	}
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const MethodAPIKey = "api_key"
+
+type APIKey struct {
+	ID    string   `json:"id"`
+	Hash  string   `json:"hash"`
+	Modes []string `json:"modes"`
+	Tools []string `json:"tools"`
+}
+
+type KeyStore struct {
+	keys map[string]APIKey
+}
+
+func HashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewKeyStore(keys []APIKey) (*KeyStore, error) {
+	store := &KeyStore{keys: make(map[string]APIKey, len(keys))}
+	for _, key := range keys {
+		key.Hash = strings.ToLower(strings.TrimSpace(key.Hash))
+		if strings.TrimSpace(key.ID) == "" {
+			return nil, errors.New("api key id is required")
+		}
+		if decoded, err := hex.DecodeString(key.Hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("api key %s: hash must be a hex sha256 digest", key.ID)
+		}
+		if len(key.Modes) == 0 {
+			return nil, fmt.Errorf("api key %s: at least one mode is required", key.ID)
+		}
+		if key.Tools == nil {
+			key.Tools = []string{Wildcard}
+		}
+		if _, ok := store.keys[key.Hash]; ok {
+			return nil, fmt.Errorf("api key %s: duplicate hash", key.ID)
+		}
+		store.keys[key.Hash] = key
+	}
+	return store, nil
+}
+
+func (s *KeyStore) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.keys)
+}
+
+func (s *KeyStore) Authenticate(raw string) (Principal, error) {
+	if s == nil || raw == "" {
+		return Principal{}, ErrUnauthenticated
+	}
+	key, ok := s.keys[HashKey(raw)]
+	if !ok {
+		return Principal{}, ErrUnauthenticated
+	}
+	return Principal{ID: key.ID, Method: MethodAPIKey, Modes: key.Modes, Tools: key.Tools}, nil
+}
+
+func LoadKeyFile(path string) ([]APIKey, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read api key file: %w", err)
+	}
+	var file struct {
+		Keys []APIKey `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &file); err != nil {
+		return nil, fmt.Errorf("decode api key file: %w", err)
+	}
+	return file.Keys, nil
+}
+
+func ParseKeySpec(spec string) ([]APIKey, error) {
+	keys := make([]APIKey, 0)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) < 3 || len(parts) > 4 {
+			return nil, fmt.Errorf("api key spec %q must be id:sha256:modes[:tools]", entry)
+		}
+		key := APIKey{ID: parts[0], Hash: parts[1], Modes: splitList(parts[2])}
+		if len(parts) == 4 {
+			key.Tools = splitList(parts[3])
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
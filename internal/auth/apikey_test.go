@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseKeySpec(t *testing.T) {
+	hash := HashKey("secret")
+	tests := []struct {
+		name    string
+		spec    string
+		modes   []string
+		tools   []string
+		wantErr bool
+	}{
+		{"tools omitted", "k1:" + hash + ":student_analysis", []string{"student_analysis"}, []string{Wildcard}, false},
+		{"tools listed", "k1:" + hash + ":student_analysis|customer_support:query_student_scores", []string{"student_analysis", "customer_support"}, []string{"query_student_scores"}, false},
+		{"tools empty", "k1:" + hash + ":student_analysis:", []string{"student_analysis"}, []string{}, false},
+		{"too few parts", "k1:" + hash, nil, nil, true},
+		{"too many parts", "k1:" + hash + ":a:b:c", nil, nil, true},
+		{"bad hash", "k1:abc:student_analysis", nil, nil, true},
+		{"no modes", "k1:" + hash + ":", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseKeySpec(tt.spec)
+			var store *KeyStore
+			if err == nil {
+				store, err = NewKeyStore(keys)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			principal, err := store.Authenticate("secret")
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if principal.ID != "k1" || principal.Method != MethodAPIKey {
+				t.Fatalf("principal = %+v", principal)
+			}
+			if !slices.Equal(principal.Modes, tt.modes) || !slices.Equal(principal.Tools, tt.tools) {
+				t.Fatalf("modes = %v tools = %v, want %v %v", principal.Modes, principal.Tools, tt.modes, tt.tools)
+			}
+		})
+	}
+}
+
+func TestKeyWithoutToolsAllowsMainFlowTools(t *testing.T) {
+	keys, err := ParseKeySpec("k1:" + HashKey("secret") + ":student_analysis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewKeyStore(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal, err := store.Authenticate("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range []string{"query_student_scores", "query_student_score_history"} {
+		if !principal.AllowsTool(tool) {
+			t.Errorf("AllowsTool(%q) = false", tool)
+		}
+	}
+}
+
+func TestKeyStoreRejectsUnknownKeys(t *testing.T) {
+	store, err := NewKeyStore([]APIKey{{ID: "k1", Hash: HashKey("secret"), Modes: []string{"student_analysis"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, raw := range []string{"", "other"} {
+		if _, err := store.Authenticate(raw); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("Authenticate(%q) error = %v, want ErrUnauthenticated", raw, err)
+		}
+	}
+	if _, err := NewKeyStore([]APIKey{
+		{ID: "k1", Hash: HashKey("secret"), Modes: []string{"a"}},
+		{ID: "k2", Hash: HashKey("secret"), Modes: []string{"a"}},
+	}); err == nil {
+		t.Error("duplicate hashes were accepted")
+	}
+}
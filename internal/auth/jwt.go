@@ -0,0 +1,273 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	MethodJWT = "jwt"
+
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+)
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func LoadJWKSFile(path string) (JWKS, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return JWKS{}, fmt.Errorf("read jwks file: %w", err)
+	}
+	var jwks JWKS
+	if err := json.Unmarshal(body, &jwks); err != nil {
+		return JWKS{}, fmt.Errorf("decode jwks file: %w", err)
+	}
+	return jwks, nil
+}
+
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	Role      string   `json:"role"`
+	StudentID int      `json:"student_id"`
+	ClassIDs  []int    `json:"class_ids"`
+	Modes     []string `json:"modes"`
+	Tools     []string `json:"tools"`
+}
+
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("aud must be a string or array of strings")
+	}
+	*a = many
+	return nil
+}
+
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+type verificationKey struct {
+	alg    string
+	secret []byte
+	public *rsa.PublicKey
+}
+
+type JWTVerifier struct {
+	cfg  JWTConfig
+	keys map[string]verificationKey
+	now  func() time.Time
+}
+
+func NewJWTVerifier(jwks JWKS, cfg JWTConfig) (*JWTVerifier, error) {
+	if cfg.Leeway <= 0 {
+		cfg.Leeway = 30 * time.Second
+	}
+	v := &JWTVerifier{cfg: cfg, keys: make(map[string]verificationKey, len(jwks.Keys)), now: time.Now}
+	for i, jwk := range jwks.Keys {
+		key, err := parseJWK(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %d: %w", i, err)
+		}
+		if _, ok := v.keys[jwk.Kid]; ok {
+			return nil, fmt.Errorf("jwks key %d: duplicate kid %q", i, jwk.Kid)
+		}
+		v.keys[jwk.Kid] = key
+	}
+	return v, nil
+}
+
+func parseJWK(jwk JWK) (verificationKey, error) {
+	switch jwk.Kty {
+	case "oct":
+		if jwk.Alg != "" && jwk.Alg != "HS256" {
+			return verificationKey{}, fmt.Errorf("unsupported alg %s for oct key", jwk.Alg)
+		}
+		secret, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(jwk.K, "="))
+		if err != nil || len(secret) == 0 {
+			return verificationKey{}, errors.New("oct key requires base64url k")
+		}
+		return verificationKey{alg: "HS256", secret: secret}, nil
+	case "RSA":
+		if jwk.Alg != "" && jwk.Alg != "RS256" {
+			return verificationKey{}, fmt.Errorf("unsupported alg %s for RSA key", jwk.Alg)
+		}
+		n, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(jwk.N, "="))
+		if err != nil || len(n) == 0 {
+			return verificationKey{}, errors.New("RSA key requires base64url n")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(jwk.E, "="))
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return verificationKey{}, errors.New("RSA key requires base64url e")
+		}
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		return verificationKey{alg: "RS256", public: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}}, nil
+	default:
+		return verificationKey{}, fmt.Errorf("unsupported kty %q", jwk.Kty)
+	}
+}
+
+func (v *JWTVerifier) Len() int {
+	if v == nil {
+		return 0
+	}
+	return len(v.keys)
+}
+
+func (v *JWTVerifier) Verify(token string) (Principal, error) {
+	claims, err := v.verify(token)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	principal := Principal{
+		ID:        "jwt:" + claims.Subject,
+		Method:    MethodJWT,
+		Modes:     claims.Modes,
+		Tools:     claims.Tools,
+		Subject:   claims.Subject,
+		Role:      claims.Role,
+		StudentID: claims.StudentID,
+		ClassIDs:  claims.ClassIDs,
+	}
+	if len(principal.Modes) == 0 {
+		principal.Modes = []string{Wildcard}
+	}
+	if len(principal.Tools) == 0 {
+		principal.Tools = []string{Wildcard}
+	}
+	return principal, nil
+}
+
+func (v *JWTVerifier) verify(token string) (Claims, error) {
+	if v == nil || len(v.keys) == 0 {
+		return Claims{}, errors.New("jwt verification not configured")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, errors.New("token must have three segments")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, fmt.Errorf("header: %w", err)
+	}
+	key, err := v.lookup(header.Kid)
+	if err != nil {
+		return Claims{}, err
+	}
+	if header.Alg != key.alg {
+		return Claims{}, fmt.Errorf("alg %q does not match key", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, errors.New("signature is not base64url")
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	switch key.alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, key.secret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return Claims{}, errors.New("invalid signature")
+		}
+	case "RS256":
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(key.public, crypto.SHA256, digest[:], signature); err != nil {
+			return Claims{}, errors.New("invalid signature")
+		}
+	}
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, fmt.Errorf("claims: %w", err)
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return Claims{}, err
+	}
+	return claims, nil
+}
+
+func (v *JWTVerifier) lookup(kid string) (verificationKey, error) {
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return verificationKey{}, fmt.Errorf("unknown kid %q", kid)
+}
+
+func (v *JWTVerifier) validateClaims(claims Claims) error {
+	now := v.now()
+	if strings.TrimSpace(claims.Subject) == "" {
+		return errors.New("sub is required")
+	}
+	if claims.ExpiresAt == 0 {
+		return errors.New("exp is required")
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(v.cfg.Leeway)) {
+		return errors.New("token expired")
+	}
+	if claims.NotBefore != 0 && now.Add(v.cfg.Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return errors.New("token not yet valid")
+	}
+	if v.cfg.Issuer != "" && claims.Issuer != v.cfg.Issuer {
+		return errors.New("unexpected issuer")
+	}
+	if v.cfg.Audience != "" && !slices.Contains(claims.Audience, v.cfg.Audience) {
+		return errors.New("unexpected audience")
+	}
+	return nil
+}
+
+func decodeSegment(segment string, target any) error {
+	body, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("segment is not base64url")
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return errors.New("segment is not valid JSON")
+	}
+	return nil
+}
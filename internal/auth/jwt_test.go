@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testNow    = time.Unix(1_700_000_000, 0)
+	testSecret = []byte("0123456789abcdef0123456789abcdef")
+)
+
+func encodeSegment(t *testing.T, value any) string {
+	t.Helper()
+	body, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+func signHS256(t *testing.T, header, claims map[string]any) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, header, claims map[string]any) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims(overrides map[string]any) map[string]any {
+	claims := map[string]any{
+		"sub":        "s12",
+		"iss":        "https://idp.example.com",
+		"aud":        "omnibase",
+		"exp":        testNow.Add(time.Hour).Unix(),
+		"role":       RoleStudent,
+		"student_id": 12,
+	}
+	for key, value := range overrides {
+		if value == nil {
+			delete(claims, key)
+			continue
+		}
+		claims[key] = value
+	}
+	return claims
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := JWKS{Keys: []JWK{
+		{Kty: "oct", Kid: "hs", K: base64.RawURLEncoding.EncodeToString(testSecret)},
+		{Kty: "RSA", Kid: "rs", Alg: "RS256", N: base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()), E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+	}}
+	verifier, err := NewJWTVerifier(jwks, JWTConfig{Issuer: "https://idp.example.com", Audience: "omnibase", Leeway: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier.now = func() time.Time { return testNow }
+
+	hs := map[string]any{"alg": "HS256", "kid": "hs"}
+	rs := map[string]any{"alg": "RS256", "kid": "rs"}
+	tampered := signHS256(t, hs, validClaims(nil))
+	parts := strings.Split(tampered, ".")
+	parts[1] = encodeSegment(t, validClaims(map[string]any{"student_id": 13}))
+	tampered = strings.Join(parts, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"valid HS256", signHS256(t, hs, validClaims(nil)), ""},
+		{"valid RS256", signRS256(t, rsaKey, rs, validClaims(nil)), ""},
+		{"audience array", signHS256(t, hs, validClaims(map[string]any{"aud": []string{"other", "omnibase"}})), ""},
+		{"expired within leeway", signHS256(t, hs, validClaims(map[string]any{"exp": testNow.Add(-30 * time.Second).Unix()})), ""},
+		{"expired", signHS256(t, hs, validClaims(map[string]any{"exp": testNow.Add(-2 * time.Minute).Unix()})), "token expired"},
+		{"not yet valid", signHS256(t, hs, validClaims(map[string]any{"nbf": testNow.Add(2 * time.Minute).Unix()})), "token not yet valid"},
+		{"missing exp", signHS256(t, hs, validClaims(map[string]any{"exp": nil})), "exp is required"},
+		{"missing sub", signHS256(t, hs, validClaims(map[string]any{"sub": nil})), "sub is required"},
+		{"wrong issuer", signHS256(t, hs, validClaims(map[string]any{"iss": "https://evil.example.com"})), "unexpected issuer"},
+		{"wrong audience", signHS256(t, hs, validClaims(map[string]any{"aud": "other"})), "unexpected audience"},
+		{"tampered claims", tampered, "invalid signature"},
+		{"unknown kid", signHS256(t, map[string]any{"alg": "HS256", "kid": "missing"}, validClaims(nil)), "unknown kid"},
+		{"alg none", encodeSegment(t, map[string]any{"alg": "none", "kid": "hs"}) + "." + encodeSegment(t, validClaims(nil)) + ".", "does not match key"},
+		{"HS256 against RSA key", signHS256(t, map[string]any{"alg": "HS256", "kid": "rs"}, validClaims(nil)), "does not match key"},
+		{"two segments", "a.b", "three segments"},
+		{"bad header", "!!.e30.sig", "header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Verify(tt.token)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrUnauthenticated) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Verify() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if principal.ID != "jwt:s12" || principal.Method != MethodJWT || principal.Role != RoleStudent || principal.StudentID != 12 {
+				t.Fatalf("principal = %+v", principal)
+			}
+			if !slices.Equal(principal.Modes, []string{Wildcard}) || !slices.Equal(principal.Tools, []string{Wildcard}) {
+				t.Errorf("modes = %v tools = %v, want wildcards", principal.Modes, principal.Tools)
+			}
+		})
+	}
+}
+
+func TestNewJWTVerifierRejectsInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []JWK
+	}{
+		{"unsupported kty", []JWK{{Kty: "EC", Kid: "a"}}},
+		{"oct without k", []JWK{{Kty: "oct", Kid: "a"}}},
+		{"oct with RS256", []JWK{{Kty: "oct", Kid: "a", Alg: "RS256", K: "c2VjcmV0"}}},
+		{"RSA without n", []JWK{{Kty: "RSA", Kid: "a", E: "AQAB"}}},
+		{"duplicate kid", []JWK{{Kty: "oct", Kid: "a", K: "c2VjcmV0"}, {Kty: "oct", Kid: "a", K: "b3RoZXI"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewJWTVerifier(JWKS{Keys: tt.keys}, JWTConfig{}); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
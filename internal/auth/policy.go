@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"omnibase/internal/schema"
+)
+
+type ClassResolver interface {
+	StudentClassIDs(ctx context.Context, studentID int) ([]int, error)
+}
+
+type ToolPolicy interface {
+	Authorize(ctx context.Context, tool string, args map[string]any) error
+}
+
+type ArgumentPolicy struct {
+	Classes ClassResolver
+}
+
+func (p ArgumentPolicy) Authorize(ctx context.Context, tool string, args map[string]any) error {
+	if err := AuthorizeTool(ctx, tool); err != nil {
+		return err
+	}
+	principal, ok := FromContext(ctx)
+	if !ok || principal.Method != MethodJWT || principal.Role == RoleAdmin {
+		return nil
+	}
+	if principal.Role != RoleStudent && principal.Role != RoleTeacher {
+		return fmt.Errorf("%w: role %q may not call %s", ErrForbidden, principal.Role, tool)
+	}
+	if err := requireScopeArgument(tool, args); err != nil {
+		return err
+	}
+	if studentID, ok, err := scopeArgument(args, "student_id"); err != nil {
+		return err
+	} else if ok {
+		if err := p.authorizeStudent(ctx, principal, studentID); err != nil {
+			return err
+		}
+	}
+	if classID, ok, err := scopeArgument(args, "class_id"); err != nil {
+		return err
+	} else if ok {
+		if err := p.authorizeClass(ctx, principal, classID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var scopeArguments = map[string]string{
+	"query_student_scores":        "student_id",
+	"query_student_score_history": "student_id",
+	"query_class_stats":           "student_id",
+	"query_grade_stats":           "student_id",
+	"query_student_classes":       "student_id",
+	"query_class_students":        "class_id",
+}
+
+func requireScopeArgument(tool string, args map[string]any) error {
+	key, ok := scopeArguments[tool]
+	if !ok {
+		return nil
+	}
+	if _, present := args[key]; !present {
+		return fmt.Errorf("%w: %s requires %s", ErrForbidden, tool, key)
+	}
+	return nil
+}
+
+func scopeArgument(args map[string]any, key string) (int, bool, error) {
+	value, present := args[key]
+	if !present {
+		return 0, false, nil
+	}
+	id, ok := schema.IntValue(value)
+	if !ok || id <= 0 {
+		return 0, false, fmt.Errorf("%w: %s must be a positive integer", ErrForbidden, key)
+	}
+	return id, true, nil
+}
+
+func (p ArgumentPolicy) authorizeStudent(ctx context.Context, principal Principal, studentID int) error {
+	switch principal.Role {
+	case RoleStudent:
+		if principal.StudentID == 0 || principal.StudentID != studentID {
+			return fmt.Errorf("%w: students may only access their own records", ErrForbidden)
+		}
+		return nil
+	default:
+		classIDs, err := p.studentClasses(ctx, studentID)
+		if err != nil {
+			return err
+		}
+		for _, classID := range classIDs {
+			if slices.Contains(principal.ClassIDs, classID) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: student %d is not in any of your classes", ErrForbidden, studentID)
+	}
+}
+
+func (p ArgumentPolicy) authorizeClass(ctx context.Context, principal Principal, classID int) error {
+	switch principal.Role {
+	case RoleStudent:
+		classIDs, err := p.studentClasses(ctx, principal.StudentID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(classIDs, classID) {
+			return fmt.Errorf("%w: students may only access their own classes", ErrForbidden)
+		}
+		return nil
+	default:
+		if !slices.Contains(principal.ClassIDs, classID) {
+			return fmt.Errorf("%w: class %d is not one of your classes", ErrForbidden, classID)
+		}
+		return nil
+	}
+}
+
+func (p ArgumentPolicy) studentClasses(ctx context.Context, studentID int) ([]int, error) {
+	if p.Classes == nil {
+		return nil, fmt.Errorf("%w: class membership cannot be resolved", ErrForbidden)
+	}
+	classIDs, err := p.Classes.StudentClassIDs(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve classes for student %d: %w", studentID, err)
+	}
+	return classIDs, nil
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type staticClasses map[int][]int
+
+func (c staticClasses) StudentClassIDs(ctx context.Context, studentID int) ([]int, error) {
+	return c[studentID], nil
+}
+
+func TestArgumentPolicyDeniesInvalidScopeArguments(t *testing.T) {
+	student := Principal{ID: "jwt:s12", Method: MethodJWT, Tools: []string{Wildcard}, Role: RoleStudent, StudentID: 12}
+	teacher := Principal{ID: "jwt:t1", Method: MethodJWT, Tools: []string{Wildcard}, Role: RoleTeacher, ClassIDs: []int{7}}
+	policy := ArgumentPolicy{Classes: staticClasses{12: {7}}}
+
+	tests := []struct {
+		name      string
+		principal Principal
+		tool      string
+		args      map[string]any
+	}{
+		{"student id with suffix", student, "query_student_scores", map[string]any{"student_id": "12abc"}},
+		{"student id with space", student, "query_student_scores", map[string]any{"student_id": " 12"}},
+		{"fractional student id", student, "query_student_scores", map[string]any{"student_id": 12.5}},
+		{"null student id", student, "query_student_scores", map[string]any{"student_id": nil}},
+		{"zero student id", student, "query_student_scores", map[string]any{"student_id": 0}},
+		{"negative student id", teacher, "query_student_scores", map[string]any{"student_id": json.Number("-12")}},
+		{"missing student id", student, "query_student_scores", map[string]any{"term": "2024-1"}},
+		{"missing student id for teacher", teacher, "query_class_stats", map[string]any{}},
+		{"missing class id", teacher, "query_class_students", map[string]any{}},
+		{"malformed class id", teacher, "query_class_students", map[string]any{"class_id": "7 or 1=1"}},
+		{"other student", student, "query_student_scores", map[string]any{"student_id": 13}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithPrincipal(context.Background(), tt.principal)
+			if err := policy.Authorize(ctx, tt.tool, tt.args); !errors.Is(err, ErrForbidden) {
+				t.Fatalf("Authorize() error = %v, want ErrForbidden", err)
+			}
+		})
+	}
+}
+
+func TestArgumentPolicyAllowsOwnRecords(t *testing.T) {
+	student := Principal{ID: "jwt:s12", Method: MethodJWT, Tools: []string{Wildcard}, Role: RoleStudent, StudentID: 12}
+	teacher := Principal{ID: "jwt:t1", Method: MethodJWT, Tools: []string{Wildcard}, Role: RoleTeacher, ClassIDs: []int{7}}
+	policy := ArgumentPolicy{Classes: staticClasses{12: {7}}}
+
+	tests := []struct {
+		name      string
+		principal Principal
+		tool      string
+		args      map[string]any
+	}{
+		{"student numeric id", student, "query_student_scores", map[string]any{"student_id": float64(12)}},
+		{"student string id", student, "query_student_scores", map[string]any{"student_id": "12"}},
+		{"teacher class student", teacher, "query_class_stats", map[string]any{"student_id": 12, "term": "2024-1"}},
+		{"teacher class", teacher, "query_class_students", map[string]any{"class_id": 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithPrincipal(context.Background(), tt.principal)
+			if err := policy.Authorize(ctx, tt.tool, tt.args); err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+)
+
+const Wildcard = "*"
+
+type Principal struct {
+	ID     string
+	Method string
+	Modes  []string
+	Tools  []string
+
+	Subject   string
+	Role      string
+	StudentID int
+	ClassIDs  []int
+}
+
+func (p Principal) AllowsMode(mode string) bool {
+	return contains(p.Modes, mode)
+}
+
+func (p Principal) AllowsTool(tool string) bool {
+	return contains(p.Tools, tool)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == Wildcard || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+func AuthorizeTool(ctx context.Context, tool string) error {
+	p, ok := FromContext(ctx)
+	if !ok || p.AllowsTool(tool) {
+		return nil
+	}
+	return fmt.Errorf("%w: tool %s is not permitted for %s", ErrForbidden, tool, p.ID)
+}
@@ -0,0 +1,180 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+var ErrOpen = errors.New("circuit breaker open")
+
+type OpenError struct {
+	Name       string
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s unavailable: %s", e.Name, ErrOpen)
+}
+
+func (e *OpenError) Is(target error) bool { return target == ErrOpen }
+
+type Config struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenMaxCalls int
+	OnStateChange    func(name string, from, to State)
+}
+
+type Breaker struct {
+	name string
+	cfg  Config
+
+	mu            sync.Mutex
+	state         State
+	failures      int
+	openedAt      time.Time
+	halfOpenCalls int
+	successes     int
+}
+
+func New(name string, cfg Config) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.HalfOpenMaxCalls <= 0 {
+		cfg.HalfOpenMaxCalls = 1
+	}
+	return &Breaker{name: name, cfg: cfg}
+}
+
+func (b *Breaker) Name() string { return b.name }
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.cfg.OpenTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+func (b *Breaker) Do(fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.record(err)
+	return err
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case StateOpen:
+		remaining := b.cfg.OpenTimeout - time.Since(b.openedAt)
+		if remaining > 0 {
+			b.mu.Unlock()
+			return &OpenError{Name: b.name, RetryAfter: remaining}
+		}
+		b.state = StateHalfOpen
+		b.halfOpenCalls = 0
+		b.successes = 0
+	case StateHalfOpen:
+		if b.halfOpenCalls >= b.cfg.HalfOpenMaxCalls {
+			b.mu.Unlock()
+			return &OpenError{Name: b.name, RetryAfter: time.Second}
+		}
+	}
+	if b.state == StateHalfOpen {
+		b.halfOpenCalls++
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+	return nil
+}
+
+func (b *Breaker) record(err error) {
+	canceled := errors.Is(err, context.Canceled)
+	failed := IsFailure(err)
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case StateClosed:
+		if canceled {
+			break
+		}
+		if !failed {
+			b.failures = 0
+			break
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.trip()
+		}
+	case StateHalfOpen:
+		if b.halfOpenCalls > 0 {
+			b.halfOpenCalls--
+		}
+		if canceled {
+			break
+		}
+		if failed {
+			b.trip()
+			break
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenMaxCalls {
+			b.state = StateClosed
+			b.failures = 0
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+func (b *Breaker) trip() {
+	b.state = StateOpen
+	b.openedAt = time.Now()
+	b.failures = 0
+	b.halfOpenCalls = 0
+	b.successes = 0
+}
+
+func (b *Breaker) notify(from, to State) {
+	if from != to && b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(b.name, from, to)
+	}
+}
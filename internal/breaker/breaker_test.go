@@ -0,0 +1,130 @@
+package breaker
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestIsFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", fmt.Errorf("call: %w", context.Canceled), false},
+		{"canceled transport", &url.Error{Op: "Post", URL: "http://llm", Err: context.Canceled}, false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"transport", &url.Error{Op: "Post", URL: "http://llm", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{"net error", &net.OpError{Op: "read", Err: errors.New("reset")}, true},
+		{"bad conn", fmt.Errorf("query: %w", driver.ErrBadConn), true},
+		{"500", &StatusError{Op: "search", StatusCode: 500}, true},
+		{"503 wrapped", fmt.Errorf("dispatch: %w", &StatusError{Op: "tool dispatch", StatusCode: 503}), true},
+		{"429", &StatusError{Op: "chat completion", StatusCode: 429}, true},
+		{"400", &StatusError{Op: "chat completion", StatusCode: 400}, false},
+		{"404", &StatusError{Op: "search", StatusCode: 404}, false},
+		{"decode", errors.New("decode response: unexpected EOF"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsFailure(tt.err); got != tt.want {
+				t.Fatalf("IsFailure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	unavailable := &StatusError{Op: "search", StatusCode: 503}
+	badRequest := &StatusError{Op: "search", StatusCode: 400}
+	tests := []struct {
+		name  string
+		calls []error
+		wait  bool
+		probe error
+		want  State
+	}{
+		{"stays closed below threshold", []error{unavailable, unavailable}, false, nil, StateClosed},
+		{"opens at threshold", []error{unavailable, unavailable, unavailable}, false, nil, StateOpen},
+		{"success resets failures", []error{unavailable, unavailable, nil, unavailable, unavailable}, false, nil, StateClosed},
+		{"client errors do not count", []error{badRequest, badRequest, badRequest, badRequest}, false, nil, StateClosed},
+		{"cancellations do not count", []error{context.Canceled, context.Canceled, context.Canceled}, false, nil, StateClosed},
+		{"half open after timeout", []error{unavailable, unavailable, unavailable}, true, nil, StateClosed},
+		{"half open probe failure reopens", []error{unavailable, unavailable, unavailable}, true, unavailable, StateOpen},
+		{"half open client error closes", []error{unavailable, unavailable, unavailable}, true, badRequest, StateClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New("qdrant", Config{FailureThreshold: 3, OpenTimeout: 20 * time.Millisecond})
+			for _, err := range tt.calls {
+				_ = b.Do(func() error { return err })
+			}
+			if tt.wait {
+				time.Sleep(30 * time.Millisecond)
+				if state := b.State(); state != StateHalfOpen {
+					t.Fatalf("state after timeout = %s, want half_open", state)
+				}
+				_ = b.Do(func() error { return tt.probe })
+			}
+			if state := b.State(); state != tt.want {
+				t.Fatalf("state = %s, want %s", state, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenBreakerRejectsCalls(t *testing.T) {
+	var transitions []string
+	b := New("llm", Config{FailureThreshold: 1, OpenTimeout: time.Minute, OnStateChange: func(name string, from, to State) {
+		transitions = append(transitions, name+":"+from.String()+"->"+to.String())
+	}})
+	_ = b.Do(func() error { return &StatusError{Op: "chat completion", StatusCode: 502} })
+
+	called := false
+	err := b.Do(func() error {
+		called = true
+		return nil
+	})
+	var openErr *OpenError
+	if called || !errors.As(err, &openErr) || !errors.Is(err, ErrOpen) {
+		t.Fatalf("Do() error = %v, called = %v, want OpenError without calling", err, called)
+	}
+	if openErr.RetryAfter <= 0 || openErr.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %s", openErr.RetryAfter)
+	}
+	if len(transitions) != 1 || transitions[0] != "llm:closed->open" {
+		t.Errorf("transitions = %v", transitions)
+	}
+}
+
+func TestHalfOpenLimitsProbes(t *testing.T) {
+	b := New("mcp", Config{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond, HalfOpenMaxCalls: 1})
+	_ = b.Do(func() error { return context.DeadlineExceeded })
+	time.Sleep(20 * time.Millisecond)
+
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- b.Do(func() error {
+			<-release
+			return nil
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := b.Do(func() error { return nil }); !errors.Is(err, ErrOpen) {
+		t.Fatalf("second half-open call error = %v, want ErrOpen", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if state := b.State(); state != StateClosed {
+		t.Fatalf("state = %s, want closed", state)
+	}
+}
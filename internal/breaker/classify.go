@@ -0,0 +1,38 @@
+package breaker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+type StatusError struct {
+	Op         string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s failed: status %d", e.Op, e.StatusCode)
+}
+
+func (e *StatusError) HTTPStatus() int { return e.StatusCode }
+
+func IsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var status interface{ HTTPStatus() int }
+	if errors.As(err, &status) {
+		code := status.HTTPStatus()
+		return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone)
+}
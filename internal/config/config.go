@@ -2,19 +2,89 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
 	HTTPAddr         string
+	HTTPMaxBodyBytes int
 	QdrantURL        string
 	QdrantAPIKey     string
 	QdrantCollection string
+	QdrantDenseName  string
+	QdrantSparseName string
+	QdrantOptional   string
+	RAGMinScore      float64
+	RAGNoContext     string
+	RAGKeywordWeight string
+	RAGMMRLambda     float64
+	RAGMMRCandidates int
+	RAGMaxPerDoc     int
+	QueryRewrite     bool
+	QueryVariants    int
+	QueryHyDE        bool
+	RerankBackend    string
+	RerankBaseURL    string
+	RerankModel      string
+	RerankCandidates int
+	RerankBatchSize  int
 	LLMBaseURL       string
 	LLMModel         string
-	MCPBaseURL       string
-	MySQLDriver      string
-	MySQLDSN         string
+
+	EmbeddingBaseURL    string
+	EmbeddingModel      string
+	EmbeddingAPIKey     string
+	EmbeddingDimensions int
+	StrictStartup       bool
+	EmbeddingBatchSize  int
+	EmbeddingMaxTokens  int
+	EmbeddingParallel   int
+
+	MCPBaseURL  string
+	MySQLDriver string
+	MySQLDSN    string
+
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+	BreakerHalfOpenMaxCalls int
+
+	LLMMaxConcurrency int
+	LLMMaxQueue       int
+	LLMQueueTimeout   time.Duration
+	LLMMaxToolRounds  int
+
+	ServiceName         string
+	TraceExportEndpoint string
+	TraceExportFile     string
+
+	APIKeys     string
+	APIKeysFile string
+
+	JWTJWKSFile string
+	JWTIssuer   string
+	JWTAudience string
+
+	RateLimits       string
+	RateLimitIdleTTL time.Duration
+
+	JobsWorkers     int
+	JobsQueueSize   int
+	JobsTimeout     time.Duration
+	JobsRetention   time.Duration
+	JobsStoreDriver string
+	JobsStoreDSN    string
+
+	WebhookSecret       string
+	WebhookAllowedHosts string
+
+	BatchParallelism int
+
+	ScorePassThreshold     float64
+	ScoreStrengthThreshold float64
+	ScoreWeaknessThreshold float64
 }
 
 func Load() (Config, error) {
@@ -23,13 +93,172 @@ func Load() (Config, error) {
 		QdrantURL:        getenvDefault("OMNIBASE_QDRANT_URL", "http://localhost:6333"),
 		QdrantAPIKey:     os.Getenv("OMNIBASE_QDRANT_API_KEY"),
 		QdrantCollection: getenvDefault("OMNIBASE_QDRANT_COLLECTION", "omnibase_docs"),
+		QdrantDenseName:  os.Getenv("OMNIBASE_QDRANT_DENSE_VECTOR"),
+		QdrantSparseName: os.Getenv("OMNIBASE_QDRANT_SPARSE_VECTOR"),
+		QdrantOptional:   os.Getenv("OMNIBASE_QDRANT_FILTER_OPTIONAL_FIELDS"),
+		RAGKeywordWeight: os.Getenv("OMNIBASE_RAG_KEYWORD_WEIGHTS"),
+		RerankBackend:    os.Getenv("OMNIBASE_RERANK_BACKEND"),
+		RerankBaseURL:    os.Getenv("OMNIBASE_RERANK_BASE_URL"),
+		RerankModel:      os.Getenv("OMNIBASE_RERANK_MODEL"),
+		RAGNoContext:     getenvDefault("OMNIBASE_RAG_NO_CONTEXT", "instruct"),
 		LLMBaseURL:       getenvDefault("OMNIBASE_LLM_BASE_URL", "http://localhost:8000"),
 		LLMModel:         getenvDefault("OMNIBASE_LLM_MODEL", "qwen2.5-coder-14b"),
+		EmbeddingAPIKey:  os.Getenv("OMNIBASE_EMBEDDING_API_KEY"),
 		MCPBaseURL:       getenvDefault("OMNIBASE_MCP_BASE_URL", "http://localhost:7000"),
 		MySQLDriver:      getenvDefault("OMNIBASE_MYSQL_DRIVER", "mysql"),
 		MySQLDSN:         os.Getenv("OMNIBASE_MYSQL_DSN"),
+
+		ServiceName:         getenvDefault("OMNIBASE_SERVICE_NAME", "omnibase"),
+		TraceExportEndpoint: os.Getenv("OMNIBASE_TRACE_EXPORT_ENDPOINT"),
+		TraceExportFile:     os.Getenv("OMNIBASE_TRACE_EXPORT_FILE"),
+
+		APIKeys:     os.Getenv("OMNIBASE_API_KEYS"),
+		APIKeysFile: os.Getenv("OMNIBASE_API_KEYS_FILE"),
+
+		JWTJWKSFile: os.Getenv("OMNIBASE_JWT_JWKS_FILE"),
+		JWTIssuer:   os.Getenv("OMNIBASE_JWT_ISSUER"),
+		JWTAudience: os.Getenv("OMNIBASE_JWT_AUDIENCE"),
+
+		RateLimits: os.Getenv("OMNIBASE_RATE_LIMITS"),
+
+		JobsStoreDriver: getenvDefault("OMNIBASE_JOBS_STORE_DRIVER", "mysql"),
+		JobsStoreDSN:    os.Getenv("OMNIBASE_JOBS_STORE_DSN"),
+
+		WebhookSecret:       os.Getenv("OMNIBASE_WEBHOOK_SECRET"),
+		WebhookAllowedHosts: os.Getenv("OMNIBASE_WEBHOOK_ALLOWED_HOSTS"),
+	}
+
+	cfg.EmbeddingBaseURL = getenvDefault("OMNIBASE_EMBEDDING_BASE_URL", cfg.LLMBaseURL)
+	cfg.EmbeddingModel = getenvDefault("OMNIBASE_EMBEDDING_MODEL", cfg.LLMModel)
+
+	var err error
+	if cfg.HTTPMaxBodyBytes, err = getenvInt("OMNIBASE_HTTP_MAX_BODY_BYTES", 1<<20); err != nil {
+		return Config{}, err
+	}
+	if cfg.BreakerFailureThreshold, err = getenvInt("OMNIBASE_BREAKER_FAILURE_THRESHOLD", 5); err != nil {
+		return Config{}, err
+	}
+	if cfg.BreakerOpenTimeout, err = getenvDuration("OMNIBASE_BREAKER_OPEN_TIMEOUT", 30*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.BreakerHalfOpenMaxCalls, err = getenvInt("OMNIBASE_BREAKER_HALF_OPEN_MAX_CALLS", 1); err != nil {
+		return Config{}, err
+	}
+	if cfg.LLMMaxConcurrency, err = getenvInt("OMNIBASE_LLM_MAX_CONCURRENCY", 4); err != nil {
+		return Config{}, err
+	}
+	if cfg.LLMMaxQueue, err = getenvInt("OMNIBASE_LLM_MAX_QUEUE", 32); err != nil {
+		return Config{}, err
+	}
+	if cfg.LLMQueueTimeout, err = getenvDuration("OMNIBASE_LLM_QUEUE_TIMEOUT", 30*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.LLMMaxToolRounds, err = getenvInt("OMNIBASE_LLM_MAX_TOOL_ROUNDS", 4); err != nil {
+		return Config{}, err
+	}
+	if cfg.RateLimitIdleTTL, err = getenvDuration("OMNIBASE_RATE_LIMIT_IDLE_TTL", 10*time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.RAGMinScore, err = getenvFloat("OMNIBASE_RAG_MIN_SCORE", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.EmbeddingDimensions, err = getenvInt("OMNIBASE_EMBEDDING_DIMENSIONS", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.StrictStartup, err = getenvBool("OMNIBASE_STRICT_STARTUP", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.EmbeddingBatchSize, err = getenvInt("OMNIBASE_EMBEDDING_BATCH_SIZE", 64); err != nil {
+		return Config{}, err
+	}
+	if cfg.EmbeddingMaxTokens, err = getenvInt("OMNIBASE_EMBEDDING_BATCH_TOKENS", 8192); err != nil {
+		return Config{}, err
+	}
+	if cfg.EmbeddingParallel, err = getenvInt("OMNIBASE_EMBEDDING_PARALLELISM", 4); err != nil {
+		return Config{}, err
+	}
+	if cfg.RAGMMRLambda, err = getenvFloat("OMNIBASE_RAG_MMR_LAMBDA", 1); err != nil {
+		return Config{}, err
+	}
+	if cfg.RAGMMRCandidates, err = getenvInt("OMNIBASE_RAG_MMR_CANDIDATES", 20); err != nil {
+		return Config{}, err
+	}
+	if cfg.RAGMaxPerDoc, err = getenvInt("OMNIBASE_RAG_MAX_PER_DOCUMENT", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.QueryRewrite, err = getenvBool("OMNIBASE_QUERY_REWRITE", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.QueryVariants, err = getenvInt("OMNIBASE_QUERY_VARIANTS", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.QueryHyDE, err = getenvBool("OMNIBASE_QUERY_HYDE", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.RerankCandidates, err = getenvInt("OMNIBASE_RERANK_CANDIDATES", 30); err != nil {
+		return Config{}, err
+	}
+	if cfg.RerankBatchSize, err = getenvInt("OMNIBASE_RERANK_BATCH_SIZE", 10); err != nil {
+		return Config{}, err
+	}
+	if cfg.ScorePassThreshold, err = getenvFloat("OMNIBASE_SCORE_PASS_THRESHOLD", 60); err != nil {
+		return Config{}, err
+	}
+	if cfg.ScoreStrengthThreshold, err = getenvFloat("OMNIBASE_SCORE_STRENGTH_THRESHOLD", 85); err != nil {
+		return Config{}, err
+	}
+	if cfg.ScoreWeaknessThreshold, err = getenvFloat("OMNIBASE_SCORE_WEAKNESS_THRESHOLD", 70); err != nil {
+		return Config{}, err
+	}
+	if cfg.BatchParallelism, err = getenvInt("OMNIBASE_BATCH_PARALLELISM", 4); err != nil {
+		return Config{}, err
+	}
+	if cfg.JobsWorkers, err = getenvInt("OMNIBASE_JOBS_WORKERS", 2); err != nil {
+		return Config{}, err
+	}
+	if cfg.JobsQueueSize, err = getenvInt("OMNIBASE_JOBS_QUEUE_SIZE", 100); err != nil {
+		return Config{}, err
+	}
+	if cfg.JobsTimeout, err = getenvDuration("OMNIBASE_JOBS_TIMEOUT", 10*time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.JobsRetention, err = getenvDuration("OMNIBASE_JOBS_RETENTION", 24*time.Hour); err != nil {
+		return Config{}, err
 	}
 
+	if cfg.RAGNoContext != "instruct" && cfg.RAGNoContext != "escalate" {
+		return Config{}, errors.New("OMNIBASE_RAG_NO_CONTEXT must be instruct or escalate")
+	}
+	if cfg.EmbeddingDimensions < 0 {
+		return Config{}, errors.New("OMNIBASE_EMBEDDING_DIMENSIONS must not be negative")
+	}
+	if cfg.EmbeddingBatchSize < 1 || cfg.EmbeddingMaxTokens < 1 || cfg.EmbeddingParallel < 1 {
+		return Config{}, errors.New("OMNIBASE_EMBEDDING_BATCH_SIZE, OMNIBASE_EMBEDDING_BATCH_TOKENS and OMNIBASE_EMBEDDING_PARALLELISM must be positive")
+	}
+	if cfg.RAGMMRLambda < 0 || cfg.RAGMMRLambda > 1 {
+		return Config{}, errors.New("OMNIBASE_RAG_MMR_LAMBDA must be between 0 and 1")
+	}
+	if cfg.RAGMMRCandidates < 1 {
+		return Config{}, errors.New("OMNIBASE_RAG_MMR_CANDIDATES must be positive")
+	}
+	if cfg.RAGMaxPerDoc < 0 {
+		return Config{}, errors.New("OMNIBASE_RAG_MAX_PER_DOCUMENT must not be negative")
+	}
+	if cfg.QueryVariants < 0 || cfg.QueryVariants > 5 {
+		return Config{}, errors.New("OMNIBASE_QUERY_VARIANTS must be between 0 and 5")
+	}
+	if (cfg.QueryVariants > 0 || cfg.QueryHyDE) && !cfg.QueryRewrite {
+		return Config{}, errors.New("OMNIBASE_QUERY_VARIANTS and OMNIBASE_QUERY_HYDE require OMNIBASE_QUERY_REWRITE")
+	}
+	if cfg.RerankBackend != "" && cfg.RerankBackend != "endpoint" && cfg.RerankBackend != "llm" {
+		return Config{}, errors.New("OMNIBASE_RERANK_BACKEND must be empty, endpoint or llm")
+	}
+	if cfg.RerankCandidates < 1 || cfg.RerankBatchSize < 1 {
+		return Config{}, errors.New("OMNIBASE_RERANK_CANDIDATES and OMNIBASE_RERANK_BATCH_SIZE must be positive")
+	}
+	if cfg.JobsStoreDSN != "" && cfg.JobsStoreDriver != "mysql" {
+		return Config{}, errors.New("OMNIBASE_JOBS_STORE_DRIVER must be mysql")
+	}
 	if cfg.HTTPAddr == "" {
 		return Config{}, errors.New("OMNIBASE_HTTP_ADDR is required")
 	}
@@ -44,3 +273,51 @@ func getenvDefault(key, fallback string) string {
 	}
 	return value
 }
+
+func getenvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func getenvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func getenvFloat(key string, fallback float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func getenvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration: %w", key, err)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,114 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"omnibase/internal/logging"
+	"omnibase/internal/schema"
+)
+
+func (n MCPToolDispatchNode) ClassStudents(ctx context.Context, classID int) ([]int, error) {
+	if err := n.authorize(ctx, "query_class_students", map[string]any{"class_id": classID}); err != nil {
+		return nil, err
+	}
+	return n.Client.ClassStudentIDs(ctx, classID)
+}
+
+func (f Flow) ExecuteBatch(ctx context.Context, input schema.BatchRequest, parallelism int, emit func(schema.BatchItem)) error {
+	logger := logging.FromContext(ctx, slog.Default())
+	studentIDs := input.StudentIDs
+	if input.ClassID != 0 {
+		var err error
+		studentIDs, err = f.MCP.ClassStudents(ctx, input.ClassID)
+		if err != nil {
+			return err
+		}
+	}
+	studentIDs = uniqueIDs(studentIDs)
+	if len(studentIDs) == 0 {
+		return nil
+	}
+	if len(studentIDs) > schema.MaxBatchStudents {
+		var verr schema.ValidationError
+		verr.Add("class_id", fmt.Sprintf("has %d students, batches allow at most %d", len(studentIDs), schema.MaxBatchStudents))
+		return verr.Err()
+	}
+	if parallelism <= 0 {
+		parallelism = 4
+	}
+
+	request := input.UserRequest
+	request.Mode = schema.ModeStudentAnalysis
+	request.StudentID = studentIDs[0]
+	normalized, err := Intercept[schema.UserRequest, schema.NormalizedRequest](f.Normalizer, f.Interceptors...).Run(ctx, request)
+	if err != nil {
+		return err
+	}
+	shared, err := f.retrieval().Run(ctx, normalized)
+	if err != nil {
+		return err
+	}
+	logger.Info("batch retrieval shared", "student_count", len(studentIDs), "passage_count", len(shared.Passages))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallelism)
+	for _, studentID := range studentIDs {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func(studentID int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			item := schema.BatchItem{StudentID: studentID, Status: schema.BatchItemSucceeded}
+			result, err := f.executeStudent(ctx, shared, studentID)
+			if err != nil {
+				item.Status = schema.BatchItemFailed
+				item.Error = err.Error()
+			} else {
+				item.Result = &result
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			emit(item)
+		}(studentID)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (f Flow) executeStudent(ctx context.Context, shared schema.RAGContext, studentID int) (schema.StudentAnalysis, error) {
+	ctx = logging.With(ctx, "student_id", studentID)
+	rag := shared
+	rag.Request.StudentID = studentID
+	mcpContext, err := f.data().Run(ctx, rag)
+	if err != nil {
+		return schema.StudentAnalysis{}, err
+	}
+	response, err := Intercept[schema.MCPContext, schema.LLMResponse](f.LLM, f.Interceptors...).Run(ctx, mcpContext)
+	if err != nil {
+		return schema.StudentAnalysis{}, err
+	}
+	return Intercept[schema.LLMResponse, schema.StudentAnalysis](f.Formatter, f.Interceptors...).Run(ctx, response)
+}
+
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
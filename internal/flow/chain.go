@@ -0,0 +1,31 @@
+package flow
+
+import (
+	"context"
+
+	adkflow "github.com/google/adk-go/flow"
+)
+
+func Chain[A any, B any, C any](first adkflow.Node[A, B], second adkflow.Node[B, C]) adkflow.Node[A, C] {
+	return chainedNode[A, B, C]{first: first, second: second}
+}
+
+type chainedNode[A any, B any, C any] struct {
+	first  adkflow.Node[A, B]
+	second adkflow.Node[B, C]
+}
+
+func (n chainedNode[A, B, C]) Name() string { return n.first.Name() + "+" + n.second.Name() }
+
+func (n chainedNode[A, B, C]) Run(ctx context.Context, input A) (C, error) {
+	var zero C
+	intermediate, err := n.first.Run(ctx, input)
+	if err != nil {
+		return zero, err
+	}
+	output, err := n.second.Run(ctx, intermediate)
+	if err != nil {
+		return zero, err
+	}
+	return output, nil
+}
@@ -0,0 +1,97 @@
+package flow
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"omnibase/internal/schema"
+)
+
+const citationSnippetLength = 200
+
+var citationPattern = regexp.MustCompile(`(\s*)\[(\d+)\]`)
+
+func resolveCitations(response *schema.StudentAnalysis, passages []schema.Passage) ([]schema.Citation, []int) {
+	if len(passages) == 0 {
+		return nil, nil
+	}
+	fields := []*string{&response.Summary, &response.Analysis.Trend}
+	for i := range response.Recommendations {
+		fields = append(fields, &response.Recommendations[i].Action, &response.Recommendations[i].Example)
+	}
+	cited := make(map[int]bool)
+	invalid := make(map[int]bool)
+	for _, field := range fields {
+		*field = rewriteCitations(*field, len(passages), cited, invalid)
+	}
+
+	citations := make([]schema.Citation, 0, len(cited))
+	for index := range cited {
+		passage := passages[index-1]
+		citations = append(citations, schema.Citation{
+			Index:     index,
+			PassageID: passage.ID,
+			Title:     passage.Title,
+			Source:    passage.Source,
+			URL:       passage.URL,
+			Snippet:   snippet(passage.Text, citationSnippetLength),
+		})
+	}
+	sort.Slice(citations, func(i, j int) bool { return citations[i].Index < citations[j].Index })
+	invalidIndexes := make([]int, 0, len(invalid))
+	for index := range invalid {
+		invalidIndexes = append(invalidIndexes, index)
+	}
+	sort.Ints(invalidIndexes)
+	return citations, invalidIndexes
+}
+
+func rewriteCitations(text string, passages int, cited, invalid map[int]bool) string {
+	var b strings.Builder
+	last, previousCitation := 0, -1
+	for _, match := range citationPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[0], match[1]
+		index, err := strconv.Atoi(text[match[4]:match[5]])
+		if err != nil || index < 1 || !citationPosition(text, start, match[3] > match[2], previousCitation) {
+			continue
+		}
+		previousCitation = end
+		b.WriteString(text[last:start])
+		if index <= passages {
+			cited[index] = true
+			b.WriteString(text[start:end])
+		} else {
+			invalid[index] = true
+		}
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func citationPosition(text string, start int, spaced bool, previousCitation int) bool {
+	if spaced || start == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:start])
+	if r == ']' {
+		return start == previousCitation
+	}
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != ')'
+}
+
+func snippet(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)[:limit]
+	if cut := strings.LastIndex(string(runes), " "); cut > limit/2 {
+		return string(runes)[:cut] + "…"
+	}
+	return string(runes) + "…"
+}
@@ -9,20 +9,42 @@ import (
 )
 
 type Flow struct {
-	Normalizer RequestNormalizerNode
-	RAG        RAGRetrievalNode
-	MCP        MCPToolDispatchNode
-	LLM        LLMCompletionNode
-	Formatter  ResponseFormatterNode
+	Normalizer   RequestNormalizerNode
+	Rewrite      *QueryRewriteNode
+	RAG          RAGRetrievalNode
+	Rerank       *RerankNode
+	MCP          MCPToolDispatchNode
+	Analytics    ScoreAnalyticsNode
+	LLM          LLMCompletionNode
+	Formatter    ResponseFormatterNode
+	Interceptors []Interceptor
 }
 
 func (f Flow) Execute(ctx context.Context, input schema.UserRequest) (schema.StudentAnalysis, error) {
 	pipeline := adkflow.Pipeline[schema.UserRequest, schema.NormalizedRequest, schema.RAGContext, schema.MCPContext, schema.LLMResponse, schema.StudentAnalysis]{
-		First:  f.Normalizer,
-		Second: f.RAG,
-		Third:  f.MCP,
-		Fourth: f.LLM,
-		Fifth:  f.Formatter,
+		First:  Intercept[schema.UserRequest, schema.NormalizedRequest](f.Normalizer, f.Interceptors...),
+		Second: f.retrieval(),
+		Third:  f.data(),
+		Fourth: Intercept[schema.MCPContext, schema.LLMResponse](f.LLM, f.Interceptors...),
+		Fifth:  Intercept[schema.LLMResponse, schema.StudentAnalysis](f.Formatter, f.Interceptors...),
 	}
 	return pipeline.Execute(ctx, input)
 }
+
+func (f Flow) retrieval() adkflow.Node[schema.NormalizedRequest, schema.RAGContext] {
+	rag := Intercept[schema.NormalizedRequest, schema.RAGContext](f.RAG, f.Interceptors...)
+	if f.Rewrite != nil {
+		rag = Chain(Intercept[schema.NormalizedRequest, schema.NormalizedRequest](*f.Rewrite, f.Interceptors...), rag)
+	}
+	if f.Rerank != nil {
+		rag = Chain(rag, Intercept[schema.RAGContext, schema.RAGContext](*f.Rerank, f.Interceptors...))
+	}
+	return rag
+}
+
+func (f Flow) data() adkflow.Node[schema.RAGContext, schema.MCPContext] {
+	return Chain(
+		Intercept[schema.RAGContext, schema.MCPContext](f.MCP, f.Interceptors...),
+		Intercept[schema.MCPContext, schema.MCPContext](f.Analytics, f.Interceptors...),
+	)
+}
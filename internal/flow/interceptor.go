@@ -0,0 +1,139 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	adkflow "github.com/google/adk-go/flow"
+
+	"omnibase/internal/logging"
+	"omnibase/internal/tracing"
+)
+
+type Handler func(ctx context.Context, input any) (any, error)
+
+type Interceptor func(ctx context.Context, node string, input any, next Handler) (any, error)
+
+type NodeObserver interface {
+	ObserveNode(node string, duration time.Duration, err error)
+}
+
+type validator interface {
+	Validate() error
+}
+
+func DefaultInterceptors(logger *slog.Logger, observer NodeObserver) []Interceptor {
+	interceptors := []Interceptor{TracingInterceptor(), LoggingInterceptor(logger)}
+	if observer != nil {
+		interceptors = append(interceptors, MetricsInterceptor(observer))
+	}
+	return append(interceptors, RecoveryInterceptor())
+}
+
+func Intercept[I any, O any](node adkflow.Node[I, O], interceptors ...Interceptor) adkflow.Node[I, O] {
+	return interceptedNode[I, O]{node: node, interceptors: interceptors}
+}
+
+type interceptedNode[I any, O any] struct {
+	node         adkflow.Node[I, O]
+	interceptors []Interceptor
+}
+
+func (n interceptedNode[I, O]) Name() string { return n.node.Name() }
+
+func (n interceptedNode[I, O]) Run(ctx context.Context, input I) (O, error) {
+	var zero O
+	name := n.node.Name()
+	handler := func(ctx context.Context, input any) (any, error) {
+		typed, ok := input.(I)
+		if !ok {
+			return nil, fmt.Errorf("%s: unexpected input type %T", name, input)
+		}
+		return n.node.Run(ctx, typed)
+	}
+	handler = validate(handler)
+	for i := len(n.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := n.interceptors[i], handler
+		handler = func(ctx context.Context, input any) (any, error) {
+			return interceptor(ctx, name, input, next)
+		}
+	}
+	output, err := handler(ctx, input)
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := output.(O)
+	if !ok {
+		return zero, fmt.Errorf("%s: unexpected output type %T", name, output)
+	}
+	return typed, nil
+}
+
+func TracingInterceptor() Interceptor {
+	return func(ctx context.Context, node string, input any, next Handler) (any, error) {
+		ctx, span := tracing.Start(ctx, "node "+node, tracing.SpanKindInternal)
+		span.SetAttribute("flow.node", node)
+		output, err := next(ctx, input)
+		span.Finish(err)
+		return output, err
+	}
+}
+
+func LoggingInterceptor(logger *slog.Logger) Interceptor {
+	return func(ctx context.Context, node string, input any, next Handler) (any, error) {
+		nodeLogger, ctx := logging.WithComponent(ctx, logger, node)
+		start := time.Now()
+		output, err := next(ctx, input)
+		duration := time.Since(start)
+		if err != nil {
+			nodeLogger.Error("node failed", "error", err.Error(), "duration_ms", duration.Milliseconds())
+			return output, err
+		}
+		nodeLogger.Info("node completed", "duration_ms", duration.Milliseconds())
+		return output, nil
+	}
+}
+
+func MetricsInterceptor(observer NodeObserver) Interceptor {
+	return func(ctx context.Context, node string, input any, next Handler) (any, error) {
+		start := time.Now()
+		output, err := next(ctx, input)
+		observer.ObserveNode(node, time.Since(start), err)
+		return output, err
+	}
+}
+
+func RecoveryInterceptor() Interceptor {
+	return func(ctx context.Context, node string, input any, next Handler) (output any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logging.FromContext(ctx, slog.Default()).Error("node panicked", "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+				output, err = nil, fmt.Errorf("%s panicked: %v", node, recovered)
+			}
+		}()
+		return next(ctx, input)
+	}
+}
+
+func validate(next Handler) Handler {
+	return func(ctx context.Context, input any) (any, error) {
+		if v, ok := input.(validator); ok {
+			if err := v.Validate(); err != nil {
+				return nil, err
+			}
+		}
+		output, err := next(ctx, input)
+		if err != nil {
+			return output, err
+		}
+		if v, ok := output.(validator); ok {
+			if err := v.Validate(); err != nil {
+				return nil, err
+			}
+		}
+		return output, nil
+	}
+}
@@ -5,14 +5,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
+	"sync"
 
 	adkflow "github.com/google/adk-go/flow"
 
+	"omnibase/internal/analytics"
+	"omnibase/internal/auth"
 	"omnibase/internal/llm"
 	"omnibase/internal/logging"
 	"omnibase/internal/mcp"
+	"omnibase/internal/metrics"
 	"omnibase/internal/qdrant"
+	"omnibase/internal/retrieval"
 	"omnibase/internal/schema"
 )
 
@@ -21,14 +28,9 @@ type RequestNormalizerNode struct{}
 func (n RequestNormalizerNode) Name() string { return "request_normalizer" }
 
 func (n RequestNormalizerNode) Run(ctx context.Context, input schema.UserRequest) (schema.NormalizedRequest, error) {
-	logger := logging.FromContext(ctx, nil)
-	if err := input.Validate(); err != nil {
-		return schema.NormalizedRequest{}, err
-	}
+	logger := logging.FromContext(ctx, slog.Default())
 	mode := strings.ToLower(strings.TrimSpace(input.Mode))
-	switch mode {
-	case "customer_support", "student_analysis":
-	default:
+	if !schema.IsSupportedMode(mode) {
 		return schema.NormalizedRequest{}, fmt.Errorf("unsupported mode: %s", input.Mode)
 	}
 	output := schema.NormalizedRequest{
@@ -38,98 +40,331 @@ func (n RequestNormalizerNode) Run(ctx context.Context, input schema.UserRequest
 		Message:   strings.TrimSpace(input.Message),
 		StudentID: input.StudentID,
 		Term:      strings.TrimSpace(input.Term),
+		FromTerm:  strings.TrimSpace(input.FromTerm),
+		ToTerm:    strings.TrimSpace(input.ToTerm),
 	}
-	if err := output.Validate(); err != nil {
-		return schema.NormalizedRequest{}, err
-	}
-	if logger != nil {
-		logger.Info("normalized request", "mode", output.Mode)
+	if input.Filters != nil {
+		output.Filters = input.Filters.Fields()
 	}
+	logger.Info("normalized request", "mode", output.Mode)
 	return output, nil
 }
 
 var _ adkflow.Node[schema.UserRequest, schema.NormalizedRequest] = (*RequestNormalizerNode)(nil)
 
 type RAGRetrievalNode struct {
-	LLM    *llm.Client
-	Qdrant *qdrant.Client
-	TopK   int
+	Embedder       *llm.Client
+	Qdrant         *qdrant.Client
+	TopK           int
+	MinScore       float64
+	KeywordWeights map[string]float64
+	Diversity      retrieval.Diversity
+	WithVectors    bool
+	Metrics        *metrics.Metrics
 }
 
 func (n RAGRetrievalNode) Name() string { return "rag_retrieval" }
 
 func (n RAGRetrievalNode) Run(ctx context.Context, input schema.NormalizedRequest) (schema.RAGContext, error) {
-	logger := logging.FromContext(ctx, nil)
-	if err := input.Validate(); err != nil {
-		return schema.RAGContext{}, err
-	}
+	logger := logging.FromContext(ctx, slog.Default())
 	if n.TopK <= 0 {
 		n.TopK = 5
 	}
-	vector, err := n.LLM.Embed(ctx, input.Message)
+	limit := n.TopK
+	if n.Diversity.Enabled() {
+		limit = max(limit, n.Diversity.Candidates)
+	}
+	keywordWeight := n.keywordWeight(input.Mode)
+	queries := input.SearchQueries()
+	texts := make([]string, len(queries))
+	for i, query := range queries {
+		texts[i] = query.Text
+	}
+	vectors, err := n.Embedder.EmbedBatch(ctx, texts)
 	if err != nil {
 		return schema.RAGContext{}, err
 	}
-	passages, err := n.Qdrant.Search(ctx, vector, n.TopK)
-	if err != nil {
+	lists := make([]retrieval.RankedList, 2*len(queries))
+	dropped := make([]int, 2*len(queries))
+	similarities := make([]map[string]float64, len(queries))
+	errs := make([]error, 2*len(queries))
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lists[2*i].Passages, dropped[2*i], errs[2*i] = n.denseSearch(ctx, vectors[i], limit, input.Filters)
+			lists[2*i].Name, lists[2*i].Weight = retrieval.ScoreDense, 1-keywordWeight
+		}()
+		if keywordWeight > 0 && query.Kind != schema.QueryHypothetical {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				lists[2*i+1].Passages, similarities[i], dropped[2*i+1], errs[2*i+1] = n.keywordSearch(ctx, query.Text, vectors[i], limit, input.Filters)
+				lists[2*i+1].Name, lists[2*i+1].Weight = retrieval.ScoreKeyword, keywordWeight
+			}()
+		}
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
 		return schema.RAGContext{}, err
 	}
-	if logger != nil {
-		logger.Info("rag retrieved", "passage_count", len(passages))
+	var belowThreshold, keywordCount int
+	similarity := make(map[string]float64)
+	for i := range queries {
+		belowThreshold += dropped[2*i] + dropped[2*i+1]
+		keywordCount += len(lists[2*i+1].Passages)
+		for id, score := range similarities[i] {
+			if best, ok := similarity[id]; !ok || score > best {
+				similarity[id] = score
+			}
+		}
+	}
+	if belowThreshold > 0 {
+		n.Metrics.AddRAGBelowThreshold(belowThreshold)
+	}
+	passages := lists[0].Passages
+	if len(queries) > 1 || keywordWeight > 0 {
+		passages = retrieval.Fuse(lists, limit)
+		for i := range passages {
+			if _, ok := passages[i].Scores[retrieval.ScoreDense]; !ok {
+				passages[i].Score = similarity[passages[i].ID]
+				passages[i].Scores[retrieval.ScoreDense] = passages[i].Score
+			}
+		}
+	}
+	candidates := len(passages)
+	if n.Diversity.Enabled() {
+		passages = retrieval.SelectMMR(passages, n.TopK, n.Diversity.Lambda, n.Diversity.MaxPerDocument)
+		for i := range passages {
+			passages[i].Vector = nil
+		}
 	}
-	return schema.RAGContext{Request: input, Passages: passages, Embedding: vector}, nil
+	logger.Info("rag retrieved", "passage_count", len(passages), "candidates", candidates, "query_count", len(queries), "below_threshold", belowThreshold, "keyword_count", keywordCount, "keyword_weight", keywordWeight, "filters", input.Filters)
+	return schema.RAGContext{Request: input, Passages: passages, Embedding: vectors[0]}, nil
+}
+
+func (n RAGRetrievalNode) denseSearch(ctx context.Context, vector []float32, limit int, filters map[string]string) ([]schema.Passage, int, error) {
+	passages, err := n.Qdrant.Search(ctx, qdrant.SearchRequest{Vector: vector, Limit: limit, Filters: filters, WithVector: n.withVectors()})
+	if err != nil {
+		return nil, 0, err
+	}
+	retrieved := len(passages)
+	passages = slices.DeleteFunc(passages, func(passage schema.Passage) bool { return n.belowMinScore(passage.Score) })
+	return passages, retrieved - len(passages), nil
+}
+
+func (n RAGRetrievalNode) keywordSearch(ctx context.Context, text string, vector []float32, limit int, filters map[string]string) ([]schema.Passage, map[string]float64, int, error) {
+	sparse := retrieval.EncodeQuery(text)
+	if len(sparse.Indices) == 0 {
+		return nil, nil, 0, nil
+	}
+	passages, err := n.Qdrant.Search(ctx, qdrant.SearchRequest{
+		Sparse:     &qdrant.SparseVector{Indices: sparse.Indices, Values: sparse.Values},
+		Limit:      limit,
+		Filters:    filters,
+		WithVector: n.withVectors(),
+	})
+	if err != nil || len(passages) == 0 {
+		return nil, nil, 0, err
+	}
+	ids := make([]string, len(passages))
+	for i, passage := range passages {
+		ids[i] = passage.ID
+	}
+	scored, err := n.Qdrant.Search(ctx, qdrant.SearchRequest{Vector: vector, Limit: len(ids), Filters: filters, IDs: ids})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	similarity := make(map[string]float64, len(scored))
+	for _, passage := range scored {
+		similarity[passage.ID] = passage.Score
+	}
+	retrieved := len(passages)
+	passages = slices.DeleteFunc(passages, func(passage schema.Passage) bool {
+		score, ok := similarity[passage.ID]
+		return !ok || n.belowMinScore(score)
+	})
+	return passages, similarity, retrieved - len(passages), nil
+}
+
+func (n RAGRetrievalNode) belowMinScore(score float64) bool {
+	return n.MinScore != 0 && score < n.MinScore
+}
+
+func (n RAGRetrievalNode) withVectors() bool {
+	return n.WithVectors || n.Diversity.Enabled()
+}
+
+func (n RAGRetrievalNode) keywordWeight(mode string) float64 {
+	if !n.Qdrant.SparseEnabled() {
+		return 0
+	}
+	if weight, ok := n.KeywordWeights[mode]; ok {
+		return weight
+	}
+	return retrieval.DefaultKeywordWeight
 }
 
 var _ adkflow.Node[schema.NormalizedRequest, schema.RAGContext] = (*RAGRetrievalNode)(nil)
 
 type MCPToolDispatchNode struct {
-	Client *mcp.Client
+	Client  *mcp.Client
+	Policy  auth.ToolPolicy
+	Metrics *metrics.Metrics
 }
 
 func (n MCPToolDispatchNode) Name() string { return "mcp_tool_dispatch" }
 
 func (n MCPToolDispatchNode) Run(ctx context.Context, input schema.RAGContext) (schema.MCPContext, error) {
-	logger := logging.FromContext(ctx, nil)
-	if err := input.Validate(); err != nil {
-		return schema.MCPContext{}, err
-	}
+	logger := logging.FromContext(ctx, slog.Default())
 	toolName := "query_student_scores"
 	args := map[string]any{
 		"student_id": input.Request.StudentID,
 		"term":       input.Request.Term,
 	}
-	if input.Request.Mode != "student_analysis" {
+	if input.Request.Mode != schema.ModeStudentAnalysis {
 		return schema.MCPContext{Request: input.Request, Passages: input.Passages, Tool: schema.MCPResult{ToolName: "noop", Payload: map[string]any{}}}, nil
 	}
 	if input.Request.StudentID == 0 {
 		return schema.MCPContext{}, errors.New("student_id is required for student_analysis")
 	}
-	payload, err := n.Client.Dispatch(ctx, toolName, args)
+	payload, err := n.DispatchTool(ctx, toolName, args)
 	if err != nil {
 		return schema.MCPContext{}, err
 	}
-	if logger != nil {
-		logger.Info("mcp tool dispatched", "tool", toolName)
+	logger.Info("mcp tool dispatched", "tool", toolName)
+	output := schema.MCPContext{Request: input.Request, Passages: input.Passages, Tool: schema.MCPResult{ToolName: toolName, Payload: payload}}
+	output.History, err = n.dispatchOptional(ctx, "query_student_score_history", map[string]any{
+		"student_id": input.Request.StudentID,
+		"from_term":  input.Request.FromTerm,
+		"to_term":    input.Request.ToTerm,
+	})
+	if err != nil {
+		return schema.MCPContext{}, err
+	}
+	for _, statsTool := range []string{"query_class_stats", "query_grade_stats"} {
+		stats, err := n.dispatchOptional(ctx, statsTool, args)
+		if err != nil {
+			return schema.MCPContext{}, err
+		}
+		output.Stats = append(output.Stats, mcp.GroupStats(stats)...)
+	}
+	return output, nil
+}
+
+func (n MCPToolDispatchNode) DispatchTool(ctx context.Context, toolName string, args map[string]any) (map[string]any, error) {
+	if err := n.authorize(ctx, toolName, args); err != nil {
+		return nil, err
+	}
+	return n.Client.Dispatch(ctx, toolName, args)
+}
+
+func (n MCPToolDispatchNode) dispatchOptional(ctx context.Context, toolName string, args map[string]any) (map[string]any, error) {
+	logger := logging.FromContext(ctx, slog.Default())
+	if principal, ok := auth.FromContext(ctx); ok && !principal.AllowsTool(toolName) {
+		logger.Info("optional tool skipped", "tool", toolName, "reason", "tool not permitted")
+		return nil, nil
+	}
+	payload, err := n.DispatchTool(ctx, toolName, args)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		n.Metrics.IncMCPOptionalFailure(toolName)
+		logger.Warn("optional tool failed", "tool", toolName, "error", err.Error())
+		return nil, nil
+	}
+	return payload, nil
+}
+
+func (n MCPToolDispatchNode) authorize(ctx context.Context, toolName string, args map[string]any) error {
+	policy := n.Policy
+	if policy == nil {
+		policy = auth.ArgumentPolicy{}
 	}
-	return schema.MCPContext{Request: input.Request, Passages: input.Passages, Tool: schema.MCPResult{ToolName: toolName, Payload: payload}}, nil
+	return policy.Authorize(ctx, toolName, args)
 }
 
 var _ adkflow.Node[schema.RAGContext, schema.MCPContext] = (*MCPToolDispatchNode)(nil)
 
+type ScoreAnalyticsNode struct {
+	Thresholds schema.ScoreThresholds
+}
+
+func (n ScoreAnalyticsNode) Name() string { return "score_analytics" }
+
+func (n ScoreAnalyticsNode) Run(ctx context.Context, input schema.MCPContext) (schema.MCPContext, error) {
+	logger := logging.FromContext(ctx, slog.Default())
+	if input.Request.Mode != schema.ModeStudentAnalysis {
+		return input, nil
+	}
+	thresholds := n.Thresholds
+	if thresholds == (schema.ScoreThresholds{}) {
+		thresholds = analytics.DefaultThresholds
+	}
+	scores, err := analytics.ScorePoints(input.Tool.Payload)
+	if err != nil {
+		return schema.MCPContext{}, fmt.Errorf("student scores: %w", err)
+	}
+	profile := analytics.Profile(scores, input.Stats, thresholds)
+	input.Profile = &profile
+	if input.History != nil {
+		history, err := analytics.ScorePoints(input.History)
+		if err != nil {
+			return schema.MCPContext{}, fmt.Errorf("score history: %w", err)
+		}
+		trend := analytics.Trend(history)
+		input.Trend = &trend
+	}
+	logger.Info("score analytics computed", "mean", profile.Mean, "strengths", len(profile.Strengths), "weaknesses", len(profile.Weaknesses), "stat_groups", len(input.Stats))
+	return input, nil
+}
+
+var _ adkflow.Node[schema.MCPContext, schema.MCPContext] = (*ScoreAnalyticsNode)(nil)
+
+type ToolDispatcher interface {
+	DispatchTool(ctx context.Context, toolName string, args map[string]any) (map[string]any, error)
+}
+
+const (
+	NoContextInstruct = "instruct"
+	NoContextEscalate = "escalate"
+)
+
 type LLMCompletionNode struct {
-	Client *llm.Client
-	Tools  []mcp.Tool
+	Client        *llm.Client
+	Tools         []mcp.Tool
+	Dispatcher    ToolDispatcher
+	MaxToolRounds int
+	NoContext     string
+	Metrics       *metrics.Metrics
 }
 
 func (n LLMCompletionNode) Name() string { return "llm_completion" }
 
 func (n LLMCompletionNode) Run(ctx context.Context, input schema.MCPContext) (schema.LLMResponse, error) {
-	logger := logging.FromContext(ctx, nil)
-	if err := input.Validate(); err != nil {
-		return schema.LLMResponse{}, err
+	logger := logging.FromContext(ctx, slog.Default())
+	noContext := input.Request.Mode == schema.ModeCustomerSupport && len(input.Passages) == 0
+	if noContext {
+		action := n.NoContext
+		if action != NoContextEscalate {
+			action = NoContextInstruct
+		}
+		n.Metrics.IncRAGNoContext(input.Request.Mode, action)
+		logger.Info("no relevant passages", "action", action)
+		if action == NoContextEscalate {
+			return schema.LLMResponse{Mode: input.Request.Mode, Escalated: true}, nil
+		}
 	}
+	principal, authenticated := auth.FromContext(ctx)
 	tools := make([]llm.Tool, 0, len(n.Tools))
+	offered := make(map[string]bool, len(n.Tools))
 	for _, tool := range n.Tools {
+		if n.Dispatcher == nil || (authenticated && !principal.AllowsTool(tool.Name)) {
+			continue
+		}
+		offered[tool.Name] = true
 		tools = append(tools, llm.Tool{
 			Type: "function",
 			Function: llm.ToolFunction{
@@ -143,42 +378,149 @@ func (n LLMCompletionNode) Run(ctx context.Context, input schema.MCPContext) (sc
 	systemPrompt := "You are OmniBase AI. Always respond with valid JSON matching the required schema."
 	contextPrompt := ""
 	if len(input.Passages) > 0 {
-		contextPrompt = "\nRetrieved passages:\n" + strings.Join(input.Passages, "\n")
+		contextPrompt = "\nRetrieved passages:\n" + formatPassages(input.Passages)
+		contextPrompt += "\nCite the passages that support each statement inline as [n] using the numbers above. Never cite a number that is not listed."
+	}
+	if noContext {
+		contextPrompt = "\nNo relevant documents were found for this question. Do not guess or answer from general knowledge: say that you do not know and suggest contacting a support agent."
 	}
 	userPrompt := fmt.Sprintf("%s\nTool data: %s", input.Request.Message, string(payload))
+	if input.Profile != nil {
+		profile, _ := json.Marshal(input.Profile)
+		userPrompt += "\nScore profile (computed, authoritative): " + string(profile)
+		userPrompt += "\nStrengths, weaknesses and scores are already decided from the profile; write only the summary, the trend narrative and the recommendations."
+	}
+	if input.Trend != nil {
+		trend, _ := json.Marshal(input.Trend)
+		userPrompt += "\nTrend data (computed, authoritative): " + string(trend)
+		if !input.Trend.Sufficient() {
+			userPrompt += "\nOnly one term of scores is available, so do not describe a trend."
+		}
+	}
 	messages := []llm.Message{{Role: "system", Content: systemPrompt + contextPrompt}, {Role: "user", Content: userPrompt}}
 
-	content, err := n.Client.ChatCompletion(ctx, messages, tools)
+	maxRounds := n.MaxToolRounds
+	if maxRounds <= 0 {
+		maxRounds = 4
+	}
+	for round := 0; ; round++ {
+		message, err := n.Client.Complete(ctx, messages, tools)
+		if err != nil {
+			return schema.LLMResponse{}, err
+		}
+		if len(message.ToolCalls) == 0 {
+			return schema.LLMResponse{Mode: input.Request.Mode, Content: message.Content, Passages: input.Passages, Trend: input.Trend, Profile: input.Profile}, nil
+		}
+		if round == maxRounds {
+			return schema.LLMResponse{}, fmt.Errorf("llm exceeded %d tool call rounds", maxRounds)
+		}
+		messages = append(messages, message)
+		for _, call := range message.ToolCalls {
+			messages = append(messages, llm.Message{Role: "tool", ToolCallID: call.ID, Content: n.callTool(ctx, offered, call)})
+		}
+	}
+}
+
+func (n LLMCompletionNode) callTool(ctx context.Context, offered map[string]bool, call llm.ToolCall) string {
+	logger := logging.FromContext(ctx, slog.Default())
+	name := call.Function.Name
+	if !offered[name] {
+		logger.Warn("llm tool call rejected", "tool", name, "reason", "not offered")
+		return toolError(fmt.Errorf("tool %s is not available", name))
+	}
+	args := map[string]any{}
+	if strings.TrimSpace(call.Function.Arguments) != "" {
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+			logger.Warn("llm tool call rejected", "tool", name, "reason", "invalid arguments")
+			return toolError(fmt.Errorf("invalid arguments: %w", err))
+		}
+	}
+	data, err := n.Dispatcher.DispatchTool(ctx, name, args)
 	if err != nil {
-		return schema.LLMResponse{}, err
+		logger.Warn("llm tool call failed", "tool", name, "error", err.Error())
+		return toolError(err)
 	}
-	if logger != nil {
-		logger.Info("llm completion received")
+	logger.Info("llm tool call dispatched", "tool", name)
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return toolError(err)
 	}
-	return schema.LLMResponse{Content: content}, nil
+	return string(encoded)
+}
+
+func formatPassages(passages []schema.Passage) string {
+	lines := make([]string, 0, len(passages))
+	for i, passage := range passages {
+		label := fmt.Sprintf("[%d]", i+1)
+		if passage.Title != "" {
+			label += " " + passage.Title
+		} else if passage.Source != "" {
+			label += " " + passage.Source
+		}
+		if passage.Section != "" {
+			label += " / " + passage.Section
+		}
+		lines = append(lines, label+": "+passage.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func toolError(err error) string {
+	encoded, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return string(encoded)
 }
 
 var _ adkflow.Node[schema.MCPContext, schema.LLMResponse] = (*LLMCompletionNode)(nil)
 
-type ResponseFormatterNode struct{}
+const escalationSummary = "I don't know the answer to this from our documentation. Your question has been passed to a support agent."
+
+type ResponseFormatterNode struct {
+	Metrics *metrics.Metrics
+}
 
 func (n ResponseFormatterNode) Name() string { return "response_formatter" }
 
 func (n ResponseFormatterNode) Run(ctx context.Context, input schema.LLMResponse) (schema.StudentAnalysis, error) {
-	logger := logging.FromContext(ctx, nil)
-	if err := input.Validate(); err != nil {
-		return schema.StudentAnalysis{}, err
+	logger := logging.FromContext(ctx, slog.Default())
+	if input.Escalated {
+		response := schema.StudentAnalysis{
+			Mode:            input.Mode,
+			Escalated:       true,
+			Summary:         escalationSummary,
+			Analysis:        schema.StudentAnalysisDetail{Strengths: []string{}, Weaknesses: []string{}},
+			Recommendations: []schema.Recommendation{},
+		}
+		return response, response.Validate()
 	}
 	var response schema.StudentAnalysis
 	if err := json.Unmarshal([]byte(input.Content), &response); err != nil {
+		n.Metrics.IncFormatterValidationFailure("invalid_json")
 		return schema.StudentAnalysis{}, fmt.Errorf("invalid LLM JSON: %w", err)
 	}
+	response.Mode = input.Mode
+	response.Passages = input.Passages
+	citations, invalid := resolveCitations(&response, input.Passages)
+	response.Citations = citations
+	if len(invalid) > 0 {
+		n.Metrics.IncFormatterValidationFailure("invalid_citation")
+		logger.Warn("invalid citations removed", "indexes", invalid, "passage_count", len(input.Passages))
+	}
+	if input.Profile != nil {
+		response.Profile = input.Profile
+		response.Analysis.Strengths = input.Profile.Strengths
+		response.Analysis.Weaknesses = input.Profile.Weaknesses
+		response.DataSnapshot = analytics.Snapshot(*input.Profile)
+	}
+	if input.Trend != nil {
+		response.TrendData = input.Trend
+		if !input.Trend.Sufficient() {
+			response.Analysis.Trend = schema.TrendInsufficient
+		}
+	}
 	if err := response.Validate(); err != nil {
+		n.Metrics.IncFormatterValidationFailure("schema")
 		return schema.StudentAnalysis{}, err
 	}
-	if logger != nil {
-		logger.Info("response formatted")
-	}
 	return response, nil
 }
 
@@ -0,0 +1,222 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sort"
+	"sync"
+	"unicode/utf8"
+
+	adkflow "github.com/google/adk-go/flow"
+
+	"omnibase/internal/llm"
+	"omnibase/internal/logging"
+	"omnibase/internal/metrics"
+	"omnibase/internal/retrieval"
+	"omnibase/internal/schema"
+)
+
+const (
+	RerankEndpoint = "endpoint"
+	RerankLLM      = "llm"
+
+	maxRerankPassageBytes = 2000
+)
+
+type RerankNode struct {
+	Backend   string
+	Client    *llm.Client
+	TopK      int
+	BatchSize int
+	Diversity retrieval.Diversity
+	Metrics   *metrics.Metrics
+}
+
+func (n RerankNode) Name() string { return "rerank" }
+
+func (n RerankNode) Run(ctx context.Context, input schema.RAGContext) (schema.RAGContext, error) {
+	logger := logging.FromContext(ctx, slog.Default())
+	if n.TopK <= 0 {
+		n.TopK = 5
+	}
+	if n.BatchSize <= 0 {
+		n.BatchSize = 10
+	}
+	candidates := len(input.Passages)
+	if candidates <= 1 {
+		return input, nil
+	}
+	scores, err := n.score(ctx, rerankQuery(input.Request), input.Passages)
+	if err != nil {
+		if ctx.Err() != nil {
+			return schema.RAGContext{}, err
+		}
+		n.Metrics.IncRerankFallback(n.Backend)
+		logger.Warn("rerank failed, keeping retrieval order", "backend", n.Backend, "error", err.Error())
+		input.Passages = n.selectPassages(input.Passages)
+		return input, nil
+	}
+
+	passages := make([]schema.Passage, 0, len(scores))
+	var unscored []schema.Passage
+	for index, passage := range input.Passages {
+		score, ok := scores[index]
+		if !ok {
+			unscored = append(unscored, passage)
+			continue
+		}
+		passage.Scores = maps.Clone(passage.Scores)
+		if passage.Scores == nil {
+			passage.Scores = map[string]float64{retrieval.ScoreDense: passage.Score}
+		}
+		passage.Scores[retrieval.ScoreRerank] = score
+		passages = append(passages, passage)
+	}
+	sort.SliceStable(passages, func(i, j int) bool {
+		return passages[i].Scores[retrieval.ScoreRerank] > passages[j].Scores[retrieval.ScoreRerank]
+	})
+	passages = n.selectPassages(passages)
+	for _, passage := range truncatePassages(unscored, max(n.TopK-len(passages), 0)) {
+		passage.Vector = nil
+		passages = append(passages, passage)
+	}
+	input.Passages = passages
+	logger.Info("passages reranked", "backend", n.Backend, "candidates", candidates, "unscored", len(unscored), "passage_count", len(input.Passages), "diversity", n.Diversity.Enabled())
+	return input, nil
+}
+
+func rerankQuery(req schema.NormalizedRequest) string {
+	for _, query := range req.SearchQueries() {
+		if query.Kind != schema.QueryHypothetical && query.Text != "" {
+			return query.Text
+		}
+	}
+	return req.Message
+}
+
+func (n RerankNode) score(ctx context.Context, query string, passages []schema.Passage) (map[int]float64, error) {
+	switch n.Backend {
+	case RerankEndpoint:
+		documents := make([]string, 0, len(passages))
+		for _, passage := range passages {
+			documents = append(documents, passage.Text)
+		}
+		topN := n.TopK
+		if n.Diversity.Enabled() {
+			topN = max(topN, n.Diversity.Candidates)
+		}
+		results, err := n.Client.Rerank(ctx, query, documents, topN)
+		if err != nil {
+			return nil, err
+		}
+		scores := make(map[int]float64, len(results))
+		for _, result := range results {
+			scores[result.Index] = result.RelevanceScore
+		}
+		return scores, nil
+	case RerankLLM:
+		return n.scoreInBatches(ctx, query, passages)
+	default:
+		return nil, fmt.Errorf("unknown rerank backend %q", n.Backend)
+	}
+}
+
+func (n RerankNode) scoreInBatches(ctx context.Context, query string, passages []schema.Passage) (map[int]float64, error) {
+	batches := (len(passages) + n.BatchSize - 1) / n.BatchSize
+	results := make([]map[int]float64, batches)
+	errs := make([]error, batches)
+	var wg sync.WaitGroup
+	for i := range batches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := i * n.BatchSize
+			results[i], errs[i] = n.scoreWithLLM(ctx, query, passages[start:min(start+n.BatchSize, len(passages))])
+		}()
+	}
+	wg.Wait()
+	scores := make(map[int]float64, len(passages))
+	for i, batch := range results {
+		for index, score := range batch {
+			scores[i*n.BatchSize+index] = score
+		}
+	}
+	if len(scores) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	if err := errors.Join(errs...); err != nil {
+		logging.FromContext(ctx, slog.Default()).Warn("rerank batch failed, keeping its passages unscored", "backend", n.Backend, "error", err.Error())
+	}
+	return scores, nil
+}
+
+func (n RerankNode) scoreWithLLM(ctx context.Context, query string, passages []schema.Passage) (map[int]float64, error) {
+	messages := []llm.Message{
+		{Role: "system", Content: "You rate how well each numbered passage answers the query. Reply with JSON only: {\"scores\":[{\"passage\":1,\"score\":0}]}. Scores range from 0 (irrelevant) to 10 (directly answers the query). Include every passage."},
+		{Role: "user", Content: fmt.Sprintf("Query: %s\n\nPassages:\n%s", query, formatPassages(clipPassages(passages)))},
+	}
+	content, err := n.Client.ChatCompletion(ctx, messages, nil)
+	if err != nil {
+		return nil, err
+	}
+	var decoded struct {
+		Scores []struct {
+			Passage int     `json:"passage"`
+			Score   float64 `json:"score"`
+		} `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(content), &decoded); err != nil {
+		return nil, fmt.Errorf("decode rerank scores: %w", err)
+	}
+	scores := make(map[int]float64, len(decoded.Scores))
+	for _, entry := range decoded.Scores {
+		if entry.Passage < 1 || entry.Passage > len(passages) {
+			return nil, fmt.Errorf("rerank score references unknown passage %d", entry.Passage)
+		}
+		scores[entry.Passage-1] = entry.Score / 10
+	}
+	if len(scores) == 0 {
+		return nil, fmt.Errorf("rerank response contained no scores")
+	}
+	return scores, nil
+}
+
+func (n RerankNode) selectPassages(passages []schema.Passage) []schema.Passage {
+	if !n.Diversity.Enabled() {
+		return truncatePassages(passages, n.TopK)
+	}
+	passages = truncatePassages(passages, max(n.TopK, n.Diversity.Candidates))
+	passages = retrieval.SelectMMR(passages, n.TopK, n.Diversity.Lambda, n.Diversity.MaxPerDocument)
+	for i := range passages {
+		passages[i].Vector = nil
+	}
+	return passages
+}
+
+func clipPassages(passages []schema.Passage) []schema.Passage {
+	clipped := make([]schema.Passage, len(passages))
+	for i, passage := range passages {
+		if len(passage.Text) > maxRerankPassageBytes {
+			end := maxRerankPassageBytes
+			for end > 0 && !utf8.RuneStart(passage.Text[end]) {
+				end--
+			}
+			passage.Text = passage.Text[:end]
+		}
+		clipped[i] = passage
+	}
+	return clipped
+}
+
+func truncatePassages(passages []schema.Passage, limit int) []schema.Passage {
+	if len(passages) > limit {
+		return passages[:limit]
+	}
+	return passages
+}
+
+var _ adkflow.Node[schema.RAGContext, schema.RAGContext] = (*RerankNode)(nil)
@@ -0,0 +1,165 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"omnibase/internal/llm"
+	"omnibase/internal/schema"
+)
+
+func fakeRerankLLM(t *testing.T) (*llm.Client, func() (queries []string, batchSizes []int)) {
+	t.Helper()
+	var mu sync.Mutex
+	var queries []string
+	var batchSizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		prompt := req.Messages[1].Content
+		query, passages, _ := strings.Cut(strings.TrimPrefix(prompt, "Query: "), "\n\nPassages:\n")
+		type entry struct {
+			Passage int     `json:"passage"`
+			Score   float64 `json:"score"`
+		}
+		var scores []entry
+		lines := strings.Split(passages, "\n")
+		for i, line := range lines {
+			_, text, _ := strings.Cut(line, ": ")
+			if strings.Contains(text, "unscored") {
+				continue
+			}
+			score, _ := strconv.Atoi(strings.TrimPrefix(text, "p"))
+			scores = append(scores, entry{Passage: i + 1, Score: float64(score)})
+		}
+		mu.Lock()
+		queries = append(queries, query)
+		batchSizes = append(batchSizes, len(lines))
+		mu.Unlock()
+		content, _ := json.Marshal(map[string]any{"scores": scores})
+		_ = json.NewEncoder(w).Encode(map[string]any{"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": string(content)}}}})
+	}))
+	t.Cleanup(server.Close)
+	return llm.NewClient(server.URL, "test"), func() ([]string, []int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return queries, batchSizes
+	}
+}
+
+func passageIDs(passages []schema.Passage) string {
+	ids := make([]string, 0, len(passages))
+	for _, passage := range passages {
+		ids = append(ids, passage.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestRerankNodeWithLLM(t *testing.T) {
+	tests := []struct {
+		name      string
+		texts     []string
+		topK      int
+		batchSize int
+		queries   []schema.SearchQuery
+		want      string
+		wantQuery string
+		wantSizes int
+	}{
+		{
+			name:      "scored passages ordered by score",
+			texts:     []string{"p1", "p9", "p5"},
+			topK:      3,
+			batchSize: 10,
+			want:      "1,2,0",
+			wantQuery: "original question",
+			wantSizes: 3,
+		},
+		{
+			name:      "unscored passages kept after scored ones",
+			texts:     []string{"unscored a", "p2", "unscored b", "p8"},
+			topK:      4,
+			batchSize: 10,
+			want:      "3,1,0,2",
+			wantQuery: "original question",
+			wantSizes: 4,
+		},
+		{
+			name:      "candidates scored in batches",
+			texts:     []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7"},
+			topK:      3,
+			batchSize: 3,
+			want:      "6,5,4",
+			wantQuery: "original question",
+			wantSizes: 3,
+		},
+		{
+			name:      "rewritten query used",
+			texts:     []string{"p1", "p2"},
+			topK:      2,
+			batchSize: 10,
+			queries: []schema.SearchQuery{
+				{Text: "hypothetical answer", Kind: schema.QueryHypothetical},
+				{Text: "rewritten question", Kind: schema.QueryRewrite},
+			},
+			want:      "1,0",
+			wantQuery: "rewritten question",
+			wantSizes: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, calls := fakeRerankLLM(t)
+			passages := make([]schema.Passage, len(tt.texts))
+			for i, text := range tt.texts {
+				passages[i] = schema.Passage{ID: fmt.Sprint(i), Text: text, Score: 0.5}
+			}
+			node := RerankNode{Backend: RerankLLM, Client: client, TopK: tt.topK, BatchSize: tt.batchSize}
+			output, err := node.Run(context.Background(), schema.RAGContext{
+				Request:  schema.NormalizedRequest{Message: "original question", Queries: tt.queries},
+				Passages: passages,
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got := passageIDs(output.Passages); got != tt.want {
+				t.Errorf("passages = %s, want %s", got, tt.want)
+			}
+			queries, sizes := calls()
+			for _, query := range queries {
+				if query != tt.wantQuery {
+					t.Errorf("query = %q, want %q", query, tt.wantQuery)
+				}
+			}
+			for _, size := range sizes {
+				if size > tt.wantSizes {
+					t.Errorf("batch size = %d, want at most %d", size, tt.wantSizes)
+				}
+			}
+			if want := (len(tt.texts) + tt.batchSize - 1) / tt.batchSize; len(sizes) != want {
+				t.Errorf("LLM calls = %d, want %d", len(sizes), want)
+			}
+		})
+	}
+}
+
+func TestClipPassagesKeepsValidUTF8(t *testing.T) {
+	text := strings.Repeat("a", maxRerankPassageBytes-1) + "é tail"
+	clipped := clipPassages([]schema.Passage{{Text: text}, {Text: "short"}})
+	if got := clipped[0].Text; len(got) != maxRerankPassageBytes-1 || !strings.HasPrefix(text, got) {
+		t.Errorf("clipped length = %d", len(got))
+	}
+	if clipped[1].Text != "short" {
+		t.Errorf("short passage changed to %q", clipped[1].Text)
+	}
+}
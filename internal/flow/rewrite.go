@@ -0,0 +1,94 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	adkflow "github.com/google/adk-go/flow"
+
+	"omnibase/internal/llm"
+	"omnibase/internal/logging"
+	"omnibase/internal/metrics"
+	"omnibase/internal/schema"
+)
+
+type QueryRewriteNode struct {
+	Client   *llm.Client
+	Variants int
+	HyDE     bool
+	Metrics  *metrics.Metrics
+}
+
+func (n QueryRewriteNode) Name() string { return "query_rewrite" }
+
+func (n QueryRewriteNode) Run(ctx context.Context, input schema.NormalizedRequest) (schema.NormalizedRequest, error) {
+	logger := logging.FromContext(ctx, slog.Default())
+	queries, err := n.rewrite(ctx, input.Message)
+	if err != nil {
+		if ctx.Err() != nil {
+			return schema.NormalizedRequest{}, err
+		}
+		n.Metrics.IncQueryRewriteFallback()
+		logger.Warn("query rewrite failed, searching raw message", "error", err.Error())
+		return input, nil
+	}
+	input.Queries = queries
+	logger.Info("query rewritten", "query", queries[0].Text, "query_count", len(queries))
+	return input, nil
+}
+
+func (n QueryRewriteNode) rewrite(ctx context.Context, message string) ([]schema.SearchQuery, error) {
+	instructions := []string{
+		"You turn customer messages into search queries for a documentation index.",
+		"Write query as one standalone search query: drop greetings, pleasantries and complaint narrative, and keep exact identifiers such as error codes, SKUs and version numbers verbatim.",
+	}
+	format := `{"query": "..."`
+	if n.Variants > 0 {
+		instructions = append(instructions, fmt.Sprintf("Also write %d alternative phrasings of the query in variants.", n.Variants))
+		format += `, "variants": ["..."]`
+	}
+	if n.HyDE {
+		instructions = append(instructions, "Also write hypothetical_answer: a short passage, in the style of product documentation, that would answer the message.")
+		format += `, "hypothetical_answer": "..."`
+	}
+	instructions = append(instructions, "Reply with JSON only: "+format+"}.")
+	messages := []llm.Message{
+		{Role: "system", Content: strings.Join(instructions, " ")},
+		{Role: "user", Content: message},
+	}
+	content, err := n.Client.ChatCompletion(ctx, messages, nil)
+	if err != nil {
+		return nil, err
+	}
+	var decoded struct {
+		Query              string   `json:"query"`
+		Variants           []string `json:"variants"`
+		HypotheticalAnswer string   `json:"hypothetical_answer"`
+	}
+	if err := json.Unmarshal([]byte(content), &decoded); err != nil {
+		return nil, fmt.Errorf("decode rewritten query: %w", err)
+	}
+	if strings.TrimSpace(decoded.Query) == "" {
+		return nil, fmt.Errorf("rewritten query is empty")
+	}
+
+	queries := []schema.SearchQuery{{Text: strings.TrimSpace(decoded.Query), Kind: schema.QueryRewrite}}
+	seen := map[string]bool{strings.ToLower(queries[0].Text): true}
+	for _, variant := range decoded.Variants {
+		variant = strings.TrimSpace(variant)
+		if variant == "" || seen[strings.ToLower(variant)] || len(queries) > n.Variants {
+			continue
+		}
+		seen[strings.ToLower(variant)] = true
+		queries = append(queries, schema.SearchQuery{Text: variant, Kind: schema.QueryVariant})
+	}
+	if answer := strings.TrimSpace(decoded.HypotheticalAnswer); n.HyDE && answer != "" {
+		queries = append(queries, schema.SearchQuery{Text: answer, Kind: schema.QueryHypothetical})
+	}
+	return queries, nil
+}
+
+var _ adkflow.Node[schema.NormalizedRequest, schema.NormalizedRequest] = (*QueryRewriteNode)(nil)
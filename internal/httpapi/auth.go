@@ -0,0 +1,64 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"omnibase/internal/auth"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+type Authenticator struct {
+	Keys   *auth.KeyStore
+	JWT    *auth.JWTVerifier
+	Logger *slog.Logger
+}
+
+func NewAuthenticator(keys *auth.KeyStore, jwt *auth.JWTVerifier, logger *slog.Logger) *Authenticator {
+	return &Authenticator{Keys: keys, JWT: jwt, Logger: logger}
+}
+
+func (a *Authenticator) Enabled() bool {
+	return a.Keys.Len() > 0 || a.JWT.Len() > 0
+}
+
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	if !a.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.authenticate(r)
+		if err != nil {
+			a.Logger.Warn("authentication failed", "remote_addr", r.RemoteAddr, "path", r.URL.Path, "error", err.Error())
+			a.challenge(w)
+			w.Header().Set("content-type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "authentication required"})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func (a *Authenticator) authenticate(r *http.Request) (auth.Principal, error) {
+	if key := r.Header.Get(HeaderAPIKey); key != "" {
+		return a.Keys.Authenticate(key)
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("authorization"), " ")
+	if ok && strings.EqualFold(scheme, "bearer") && a.JWT.Len() > 0 {
+		return a.JWT.Verify(strings.TrimSpace(token))
+	}
+	return auth.Principal{}, auth.ErrUnauthenticated
+}
+
+func (a *Authenticator) challenge(w http.ResponseWriter) {
+	if a.Keys.Len() > 0 {
+		w.Header().Add("www-authenticate", `ApiKey realm="omnibase"`)
+	}
+	if a.JWT.Len() > 0 {
+		w.Header().Add("www-authenticate", `Bearer realm="omnibase"`)
+	}
+}
@@ -0,0 +1,99 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"omnibase/internal/schema"
+	"omnibase/internal/tracing"
+)
+
+const (
+	contentTypeNDJSON = "application/x-ndjson"
+	batchWriteTimeout = 60 * time.Second
+)
+
+func (h *Handler) ServeBatch(w http.ResponseWriter, r *http.Request) {
+	h.instrument(w, r, h.serveBatch)
+}
+
+func (h *Handler) serveBatch(w http.ResponseWriter, r *http.Request, req *schema.UserRequest) {
+	var body schema.BatchRequest
+	defer func() { *req = body.UserRequest }()
+	ctx, logger, ok := h.admit(w, r, &body.UserRequest, &body)
+	span := tracing.SpanFromContext(ctx)
+	defer span.End()
+	if !ok {
+		return
+	}
+
+	stream := acceptsNDJSON(r)
+	controller := http.NewResponseController(w)
+	response := schema.BatchResponse{RequestID: body.RequestID, TraceID: body.TraceID, ClassID: body.ClassID, Items: []schema.BatchItem{}}
+	started := false
+	start := func() {
+		if !started {
+			w.Header().Set("content-type", contentTypeNDJSON)
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+	}
+	encoder := json.NewEncoder(w)
+	emit := func(item schema.BatchItem) {
+		if item.Result != nil {
+			item.Result.RequestID = body.RequestID
+			item.Result.TraceID = body.TraceID
+		}
+		if item.Status == schema.BatchItemSucceeded {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+		_ = controller.SetWriteDeadline(time.Now().Add(batchWriteTimeout))
+		if !stream {
+			response.Items = append(response.Items, item)
+			return
+		}
+		start()
+		if err := encoder.Encode(item); err != nil {
+			logger.Warn("stream batch item failed", "error", err.Error())
+			return
+		}
+		_ = controller.Flush()
+	}
+
+	err := h.Flow.ExecuteBatch(ctx, body, h.BatchParallelism, emit)
+	span.SetAttribute("batch.succeeded", response.Succeeded)
+	span.SetAttribute("batch.failed", response.Failed)
+	if err != nil {
+		logger.Error("batch execution failed", "error", err.Error())
+		span.RecordError(err)
+		if !started {
+			h.writeFlowError(w, body.UserRequest, err)
+			return
+		}
+		_ = encoder.Encode(ErrorResponse{Error: err.Error(), RequestID: body.RequestID, TraceID: body.TraceID})
+		return
+	}
+	logger.Info("batch completed", "succeeded", response.Succeeded, "failed", response.Failed)
+	if stream {
+		start()
+		return
+	}
+	sort.Slice(response.Items, func(i, j int) bool { return response.Items[i].StudentID < response.Items[j].StudentID })
+	h.writeJSON(w, logger, http.StatusOK, response)
+}
+
+func acceptsNDJSON(r *http.Request) bool {
+	for _, value := range strings.Split(r.Header.Get("accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err == nil && mediaType == contentTypeNDJSON {
+			return true
+		}
+	}
+	return false
+}
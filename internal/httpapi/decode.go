@@ -0,0 +1,87 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"omnibase/internal/schema"
+)
+
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+type RequestError struct {
+	Status  int
+	Message string
+	Fields  []schema.FieldError
+}
+
+func (e *RequestError) Error() string { return e.Message }
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, maxBytes int64, target any) *RequestError {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("content-type"))
+	if err != nil || mediaType != "application/json" {
+		return &RequestError{Status: http.StatusUnsupportedMediaType, Message: "content-type must be application/json"}
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return decodeError(err)
+	}
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeError(err)
+		}
+		return &RequestError{Status: http.StatusBadRequest, Message: "request body must contain a single JSON object"}
+	}
+	return nil
+}
+
+func decodeError(err error) *RequestError {
+	var maxBytesErr *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &RequestError{Status: http.StatusRequestEntityTooLarge, Message: fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit)}
+	case errors.As(err, &syntaxErr):
+		return &RequestError{Status: http.StatusBadRequest, Message: fmt.Sprintf("invalid JSON at offset %d", syntaxErr.Offset)}
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return &RequestError{Status: http.StatusBadRequest, Message: "invalid JSON: unexpected end of body"}
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return &RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request",
+			Fields:  []schema.FieldError{{Field: field, Message: "must be " + typeErr.Type.String()}},
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request",
+			Fields:  []schema.FieldError{{Field: field, Message: "is not a recognized field"}},
+		}
+	default:
+		return &RequestError{Status: http.StatusBadRequest, Message: "invalid JSON"}
+	}
+}
+
+func validationRequestError(err error) (*RequestError, bool) {
+	var verr *schema.ValidationError
+	if !errors.As(err, &verr) {
+		return nil, false
+	}
+	return &RequestError{Status: http.StatusBadRequest, Message: "invalid request", Fields: verr.Fields}, true
+}
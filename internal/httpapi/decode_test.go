@@ -0,0 +1,58 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"omnibase/internal/schema"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxBytes    int64
+		wantStatus  int
+		wantField   string
+	}{
+		{"valid", "application/json", `{"mode":"customer_support","message":"hi"}`, 0, 0, ""},
+		{"content type parameters", "application/json; charset=utf-8", `{"message":"hi"}`, 0, 0, ""},
+		{"trailing whitespace", "application/json", "{\"message\":\"hi\"}\n", 0, 0, ""},
+		{"missing content type", "", `{"message":"hi"}`, 0, http.StatusUnsupportedMediaType, ""},
+		{"form content type", "application/x-www-form-urlencoded", `message=hi`, 0, http.StatusUnsupportedMediaType, ""},
+		{"empty body", "application/json", "", 0, http.StatusBadRequest, ""},
+		{"truncated", "application/json", `{"message":`, 0, http.StatusBadRequest, ""},
+		{"syntax error", "application/json", `{"message" "hi"}`, 0, http.StatusBadRequest, ""},
+		{"unknown field", "application/json", `{"message":"hi","admin":true}`, 0, http.StatusBadRequest, "admin"},
+		{"wrong type", "application/json", `{"student_id":"12"}`, 0, http.StatusBadRequest, "student_id"},
+		{"not an object", "application/json", `[1,2]`, 0, http.StatusBadRequest, "body"},
+		{"second object", "application/json", `{"message":"hi"}{"message":"again"}`, 0, http.StatusBadRequest, ""},
+		{"too large", "application/json", `{"message":"` + strings.Repeat("a", 64) + `"}`, 32, http.StatusRequestEntityTooLarge, ""},
+		{"too large after object", "application/json", `{"message":"hi"}` + strings.Repeat(" ", 64), 24, http.StatusRequestEntityTooLarge, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/v1/support/answer", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("content-type", tt.contentType)
+			}
+			var req schema.UserRequest
+			err := decodeJSON(httptest.NewRecorder(), r, tt.maxBytes, &req)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("decodeJSON() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Status != tt.wantStatus {
+				t.Fatalf("decodeJSON() error = %+v, want status %d", err, tt.wantStatus)
+			}
+			if tt.wantField != "" && (len(err.Fields) != 1 || err.Fields[0].Field != tt.wantField) {
+				t.Fatalf("fields = %+v, want %s", err.Fields, tt.wantField)
+			}
+		})
+	}
+}
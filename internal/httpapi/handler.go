@@ -1,19 +1,37 @@
 package httpapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"log/slog"
 
+	"omnibase/internal/admission"
+	"omnibase/internal/auth"
+	"omnibase/internal/breaker"
 	"omnibase/internal/flow"
 	"omnibase/internal/logging"
+	"omnibase/internal/metrics"
+	"omnibase/internal/ratelimit"
 	"omnibase/internal/schema"
+	"omnibase/internal/tracing"
 )
 
 type Handler struct {
-	Flow   flow.Flow
-	Logger *slog.Logger
+	Flow         flow.Flow
+	Logger       *slog.Logger
+	Tracer       *tracing.Tracer
+	Metrics      *metrics.Metrics
+	RateLimits   *ratelimit.Policy
+	MaxBodyBytes int64
+
+	BatchParallelism int
 }
 
 func NewHandler(flow flow.Flow, logger *slog.Logger) *Handler {
@@ -21,36 +39,217 @@ func NewHandler(flow flow.Flow, logger *slog.Logger) *Handler {
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.instrument(w, r, h.serve)
+}
+
+func (h *Handler) instrument(w http.ResponseWriter, r *http.Request, serve func(http.ResponseWriter, *http.Request, *schema.UserRequest)) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	var req schema.UserRequest
+	defer func() {
+		h.Metrics.ObserveRequest(modeLabel(req.Mode), clientLabel(r), recorder.status, time.Since(start))
+	}()
+	serve(recorder, r, &req)
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, req *schema.UserRequest) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	var req schema.UserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid JSON")
+	ctx, logger, ok := h.admit(w, r, req, req)
+	defer tracing.SpanFromContext(ctx).End()
+	if !ok {
 		return
 	}
-
-	ctx := r.Context()
-	logger, ctx := logging.WithRequest(ctx, h.Logger, req.RequestID, req.TraceID, "http")
-	result, err := h.Flow.Execute(ctx, req)
+	result, err := h.Flow.Execute(ctx, *req)
 	if err != nil {
 		logger.Error("flow execution failed", "error", err.Error())
-		h.writeError(w, http.StatusBadRequest, err.Error())
+		tracing.SpanFromContext(ctx).RecordError(err)
+		h.writeFlowError(w, *req, err)
 		return
 	}
+	result.RequestID = req.RequestID
+	result.TraceID = req.TraceID
+	h.writeJSON(w, logger, http.StatusOK, result)
+}
+
+func (h *Handler) admit(w http.ResponseWriter, r *http.Request, req *schema.UserRequest, body any) (context.Context, *slog.Logger, bool) {
+	decodeErr := decodeJSON(w, r, h.MaxBodyBytes, body)
+	idErr := resolveIDs(r, req)
+
+	ctx := h.startSpan(r, *req)
+	span := tracing.SpanFromContext(ctx)
+	echoIDs(w, *req, span)
 
+	baseLogger := h.Logger
+	principal, authenticated := auth.FromContext(ctx)
+	if authenticated {
+		baseLogger = baseLogger.With(slog.String(logging.KeyPrincipal, principal.ID))
+		span.SetAttribute(logging.KeyPrincipal, principal.ID)
+	}
+	logger, ctx := logging.WithRequest(ctx, baseLogger, req.RequestID, req.TraceID, "http")
+	if decodeErr != nil {
+		logger.Warn("decode request failed", "error", decodeErr.Error())
+		span.RecordError(decodeErr)
+		h.writeRequestError(w, *req, decodeErr)
+		return ctx, logger, false
+	}
+	if idErr != nil {
+		logger.Warn("request validation failed", "error", idErr.Error())
+		span.RecordError(idErr)
+		h.writeFlowError(w, *req, idErr)
+		return ctx, logger, false
+	}
+	if d, ok := body.(interface{ ApplyDefaults() }); ok {
+		d.ApplyDefaults()
+	}
+	validate := req.Validate
+	if v, ok := body.(interface{ Validate() error }); ok {
+		validate = v.Validate
+	}
+	if err := validate(); err != nil {
+		logger.Warn("request validation failed", "error", err.Error())
+		span.RecordError(err)
+		h.writeFlowError(w, *req, err)
+		return ctx, logger, false
+	}
+	if mode := modeLabel(req.Mode); authenticated && !principal.AllowsMode(mode) {
+		logger.Warn("mode not permitted", "mode", mode)
+		h.writeError(w, *req, http.StatusForbidden, "mode "+mode+" is not permitted")
+		return ctx, logger, false
+	}
+	if decision, limited := h.RateLimits.AllowMode(clientKey(r), modeLabel(req.Mode)); limited {
+		writeRateLimitHeaders(w, decision)
+		if !decision.Allowed {
+			logger.Warn("mode rate limit exceeded", "mode", modeLabel(req.Mode))
+			h.writeError(w, *req, http.StatusTooManyRequests, "rate limit exceeded")
+			return ctx, logger, false
+		}
+	}
+	return ctx, logger, true
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, logger *slog.Logger, status int, body any) {
 	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(result); err != nil {
+	if err := encoder.Encode(body); err != nil {
 		logger.Error("encode response failed", "error", err.Error())
+	}
+}
+
+func (h *Handler) startSpan(r *http.Request, req schema.UserRequest) context.Context {
+	ctx := r.Context()
+	if h.Tracer == nil {
+		return ctx
+	}
+	if remote, err := tracing.ParseTraceparent(r.Header.Get(HeaderTraceparent)); err == nil {
+		ctx = tracing.ContextWithRemote(ctx, remote)
+	} else if traceID, err := tracing.ParseTraceID(req.TraceID); err == nil {
+		ctx = tracing.ContextWithRemote(ctx, tracing.SpanContext{TraceID: traceID})
+	}
+	ctx, span := h.Tracer.Start(ctx, r.Method+" "+r.URL.Path, tracing.SpanKindServer)
+	span.SetAttribute("http.method", r.Method)
+	span.SetAttribute("http.target", r.URL.Path)
+	span.SetAttribute("omnibase.mode", req.Mode)
+	span.SetAttribute(logging.KeyRequestID, req.RequestID)
+	return ctx
+}
+
+func (h *Handler) writeFlowError(w http.ResponseWriter, req schema.UserRequest, err error) {
+	var saturatedErr *admission.SaturatedError
+	if errors.As(err, &saturatedErr) {
+		setRetryAfter(w, saturatedErr.RetryAfter)
+		h.writeError(w, req, http.StatusTooManyRequests, err.Error())
 		return
 	}
+	var openErr *breaker.OpenError
+	if errors.As(err, &openErr) {
+		setRetryAfter(w, openErr.RetryAfter)
+		h.writeError(w, req, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if errors.Is(err, auth.ErrForbidden) {
+		h.writeError(w, req, http.StatusForbidden, err.Error())
+		return
+	}
+	if reqErr, ok := validationRequestError(err); ok {
+		h.writeRequestError(w, req, reqErr)
+		return
+	}
+	h.writeError(w, req, http.StatusBadRequest, err.Error())
+}
+
+type ErrorResponse struct {
+	Error     string              `json:"error"`
+	Fields    []schema.FieldError `json:"fields,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+	TraceID   string              `json:"trace_id,omitempty"`
 }
 
-func (h *Handler) writeError(w http.ResponseWriter, status int, message string) {
+func (h *Handler) writeError(w http.ResponseWriter, req schema.UserRequest, status int, message string) {
+	h.writeRequestError(w, req, &RequestError{Status: status, Message: message})
+}
+
+func (h *Handler) writeRequestError(w http.ResponseWriter, req schema.UserRequest, reqErr *RequestError) {
 	w.Header().Set("content-type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+	w.WriteHeader(reqErr.Status)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{
+		Error:     reqErr.Message,
+		Fields:    reqErr.Fields,
+		RequestID: req.RequestID,
+		TraceID:   req.TraceID,
+	})
+}
+
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("retry-after", strconv.Itoa(seconds))
+}
+
+func modeLabel(mode string) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if schema.IsSupportedMode(mode) {
+		return mode
+	}
+	return "unknown"
+}
+
+func clientLabel(r *http.Request) string {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		return "anonymous"
+	}
+	if principal.Method == auth.MethodAPIKey {
+		return principal.ID
+	}
+	return principal.Method
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
 }
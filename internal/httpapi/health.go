@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"omnibase/internal/admission"
+	"omnibase/internal/breaker"
+)
+
+type HealthHandler struct {
+	Breakers []*breaker.Breaker
+	Limiters []*admission.Limiter
+}
+
+func NewHealthHandler(breakers []*breaker.Breaker, limiters []*admission.Limiter) *HealthHandler {
+	return &HealthHandler{Breakers: breakers, Limiters: limiters}
+}
+
+type HealthResponse struct {
+	Status       string                     `json:"status"`
+	Dependencies map[string]string          `json:"dependencies"`
+	Queues       map[string]admission.Stats `json:"queues"`
+}
+
+func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, false)
+}
+
+func (h *HealthHandler) ServeReady(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, true)
+}
+
+func (h *HealthHandler) serve(w http.ResponseWriter, r *http.Request, readiness bool) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	resp := HealthResponse{
+		Status:       "ok",
+		Dependencies: make(map[string]string, len(h.Breakers)),
+		Queues:       make(map[string]admission.Stats, len(h.Limiters)),
+	}
+	ready := true
+	for _, b := range h.Breakers {
+		state := b.State()
+		resp.Dependencies[b.Name()] = state.String()
+		if state != breaker.StateClosed {
+			resp.Status = "degraded"
+		}
+		if state == breaker.StateOpen {
+			ready = false
+		}
+	}
+	for _, l := range h.Limiters {
+		resp.Queues[l.Name()] = l.Stats()
+	}
+	w.Header().Set("content-type", "application/json")
+	if readiness && !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
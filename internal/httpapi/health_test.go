@@ -0,0 +1,53 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"omnibase/internal/breaker"
+)
+
+func TestHealthHandler(t *testing.T) {
+	unavailable := &breaker.StatusError{Op: "search", StatusCode: 503}
+	tests := []struct {
+		name       string
+		fail       int
+		wait       bool
+		wantStatus string
+		wantHealth int
+		wantReady  int
+	}{
+		{"closed", 0, false, "ok", http.StatusOK, http.StatusOK},
+		{"open", 1, false, "degraded", http.StatusOK, http.StatusServiceUnavailable},
+		{"half open", 1, true, "degraded", http.StatusOK, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qdrant := breaker.New("qdrant", breaker.Config{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+			for range tt.fail {
+				_ = qdrant.Do(func() error { return unavailable })
+			}
+			if tt.wait {
+				time.Sleep(30 * time.Millisecond)
+			}
+			h := NewHealthHandler([]*breaker.Breaker{breaker.New("llm", breaker.Config{}), qdrant}, nil)
+			for _, probe := range []struct {
+				serve http.HandlerFunc
+				want  int
+			}{{h.ServeHTTP, tt.wantHealth}, {h.ServeReady, tt.wantReady}} {
+				w := httptest.NewRecorder()
+				probe.serve(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+				var resp HealthResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatal(err)
+				}
+				if w.Code != probe.want || resp.Status != tt.wantStatus || resp.Dependencies["llm"] != "closed" {
+					t.Errorf("code = %d status = %s dependencies = %v, want %d %s", w.Code, resp.Status, resp.Dependencies, probe.want, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
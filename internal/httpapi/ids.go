@@ -0,0 +1,73 @@
+package httpapi
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"omnibase/internal/schema"
+	"omnibase/internal/tracing"
+)
+
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderTraceID     = "X-Trace-ID"
+	HeaderTraceparent = "traceparent"
+
+	maxRequestIDLength = 128
+)
+
+func resolveIDs(r *http.Request, req *schema.UserRequest) error {
+	req.RequestID = strings.TrimSpace(req.RequestID)
+	if req.RequestID == "" {
+		req.RequestID = headerRequestID(r)
+	}
+	if req.RequestID == "" {
+		req.RequestID = newRequestID()
+	}
+	var verr schema.ValidationError
+	bodyTraceID := strings.TrimSpace(req.TraceID)
+	traceID, err := tracing.ParseTraceID(bodyTraceID)
+	if bodyTraceID != "" && err != nil {
+		verr.Add("trace_id", "must be 32 lowercase hex characters and not all zeros")
+	}
+	switch remote, headerErr := tracing.ParseTraceparent(r.Header.Get(HeaderTraceparent)); {
+	case headerErr == nil:
+		req.TraceID = remote.TraceID.String()
+	case bodyTraceID != "" && err == nil:
+		req.TraceID = traceID.String()
+	default:
+		req.TraceID = tracing.NewTraceID().String()
+	}
+	return verr.Err()
+}
+
+func headerRequestID(r *http.Request) string {
+	value := strings.TrimSpace(r.Header.Get(HeaderRequestID))
+	if value == "" || len(value) > maxRequestIDLength {
+		return ""
+	}
+	for _, c := range value {
+		if c < 0x21 || c > 0x7e {
+			return ""
+		}
+	}
+	return value
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func echoIDs(w http.ResponseWriter, req schema.UserRequest, span *tracing.Span) {
+	w.Header().Set(HeaderRequestID, req.RequestID)
+	w.Header().Set(HeaderTraceID, req.TraceID)
+	if sc := span.Context(); sc.IsValid() {
+		w.Header().Set(HeaderTraceparent, sc.Traceparent())
+	}
+}
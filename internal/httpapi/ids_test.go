@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"omnibase/internal/schema"
+)
+
+func TestResolveIDs(t *testing.T) {
+	const (
+		bodyTrace   = "4bf92f3577b34da6a3ce929d0e0e4736"
+		headerTrace = "0af7651916cd43dd8448eb211c80319c"
+		traceparent = "00-" + headerTrace + "-b7ad6b7169203331-01"
+	)
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		req           schema.UserRequest
+		wantRequestID string
+		wantTraceID   string
+		wantFieldErr  bool
+	}{
+		{"generated", nil, schema.UserRequest{}, "", "", false},
+		{"body ids kept", nil, schema.UserRequest{RequestID: " req-1 ", TraceID: bodyTrace}, "req-1", bodyTrace, false},
+		{"header request id", map[string]string{HeaderRequestID: "gw-7"}, schema.UserRequest{}, "gw-7", "", false},
+		{"body request id wins", map[string]string{HeaderRequestID: "gw-7"}, schema.UserRequest{RequestID: "req-1"}, "req-1", "", false},
+		{"unprintable header request id", map[string]string{HeaderRequestID: "gw\x01"}, schema.UserRequest{}, "", "", false},
+		{"traceparent wins", map[string]string{HeaderTraceparent: traceparent}, schema.UserRequest{TraceID: bodyTrace}, "", headerTrace, false},
+		{"invalid traceparent ignored", map[string]string{HeaderTraceparent: "00-zz-b7ad6b7169203331-01"}, schema.UserRequest{TraceID: bodyTrace}, "", bodyTrace, false},
+		{"invalid body trace id", nil, schema.UserRequest{TraceID: "not-a-trace"}, "", "", true},
+		{"uppercase body trace id", nil, schema.UserRequest{TraceID: strings.ToUpper(bodyTrace)}, "", "", true},
+		{"zero body trace id", nil, schema.UserRequest{TraceID: strings.Repeat("0", 32)}, "", "", true},
+		{"invalid body trace id with traceparent", map[string]string{HeaderTraceparent: traceparent}, schema.UserRequest{TraceID: "bad"}, "", headerTrace, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/v1/support/answer", nil)
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			req := tt.req
+			err := resolveIDs(r, &req)
+
+			var verr *schema.ValidationError
+			if tt.wantFieldErr {
+				if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "trace_id" {
+					t.Fatalf("resolveIDs() error = %v, want trace_id field error", err)
+				}
+			} else if err != nil {
+				t.Fatalf("resolveIDs() error = %v", err)
+			}
+			if tt.wantRequestID != "" && req.RequestID != tt.wantRequestID {
+				t.Errorf("RequestID = %q, want %q", req.RequestID, tt.wantRequestID)
+			}
+			if req.RequestID == "" {
+				t.Error("RequestID is empty")
+			}
+			if tt.wantTraceID != "" && req.TraceID != tt.wantTraceID {
+				t.Errorf("TraceID = %q, want %q", req.TraceID, tt.wantTraceID)
+			}
+			if !generated.MatchString(req.TraceID) || req.TraceID == strings.Repeat("0", 32) {
+				t.Errorf("TraceID = %q is not a valid W3C trace id", req.TraceID)
+			}
+		})
+	}
+}
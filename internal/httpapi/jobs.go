@@ -0,0 +1,149 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"omnibase/internal/auth"
+	"omnibase/internal/jobs"
+	"omnibase/internal/logging"
+	"omnibase/internal/schema"
+	"omnibase/internal/tracing"
+)
+
+type JobRequest struct {
+	schema.UserRequest
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+type JobsHandler struct {
+	API      *Handler
+	Jobs     *jobs.Manager
+	Notifier *jobs.Notifier
+}
+
+func NewJobsHandler(api *Handler, manager *jobs.Manager, notifier *jobs.Notifier) *JobsHandler {
+	return &JobsHandler{API: api, Jobs: manager, Notifier: notifier}
+}
+
+func (h *JobsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.API.instrument(w, r, h.create)
+}
+
+func (h *JobsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	h.API.instrument(w, r, h.get)
+}
+
+func (h *JobsHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.API.instrument(w, r, h.cancel)
+}
+
+func (h *JobsHandler) create(w http.ResponseWriter, r *http.Request, req *schema.UserRequest) {
+	var body JobRequest
+	defer func() { *req = body.UserRequest }()
+	ctx, logger, ok := h.API.admit(w, r, &body.UserRequest, &body)
+	defer tracing.SpanFromContext(ctx).End()
+	if !ok {
+		return
+	}
+	if body.CallbackURL != "" {
+		if err := h.Notifier.ValidateURL(body.CallbackURL); err != nil {
+			h.API.writeRequestError(w, body.UserRequest, &RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "invalid request",
+				Fields:  []schema.FieldError{{Field: "callback_url", Message: err.Error()}},
+			})
+			return
+		}
+	}
+	job := jobs.Job{Request: body.UserRequest, CallbackURL: body.CallbackURL}
+	if principal, ok := auth.FromContext(ctx); ok {
+		job.Principal = &principal
+	}
+	job, err := h.Jobs.Submit(ctx, job)
+	if errors.Is(err, jobs.ErrQueueFull) {
+		logger.Warn("job rejected", "error", err.Error())
+		w.Header().Set("retry-after", "5")
+		h.API.writeError(w, body.UserRequest, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		logger.Error("job submit failed", "error", err.Error())
+		h.API.writeError(w, body.UserRequest, http.StatusInternalServerError, "job submit failed")
+		return
+	}
+	logger.Info("job queued", "job_id", job.ID)
+	w.Header().Set("location", "/v1/jobs/"+job.ID)
+	h.API.writeJSON(w, logger, http.StatusAccepted, job.View())
+}
+
+func (h *JobsHandler) get(w http.ResponseWriter, r *http.Request, req *schema.UserRequest) {
+	job, logger, ok := h.load(w, r, req)
+	if !ok {
+		return
+	}
+	h.API.writeJSON(w, logger, http.StatusOK, job.View())
+}
+
+func (h *JobsHandler) cancel(w http.ResponseWriter, r *http.Request, req *schema.UserRequest) {
+	job, logger, ok := h.load(w, r, req)
+	if !ok {
+		return
+	}
+	job, err := h.Jobs.Cancel(r.Context(), job.ID)
+	switch {
+	case errors.Is(err, jobs.ErrFinished):
+		h.API.writeError(w, *req, http.StatusConflict, err.Error())
+	case err != nil:
+		logger.Error("job cancel failed", "error", err.Error())
+		h.API.writeError(w, *req, http.StatusInternalServerError, "job cancel failed")
+	default:
+		logger.Info("job cancel requested")
+		h.API.writeJSON(w, logger, http.StatusAccepted, job.View())
+	}
+}
+
+func (h *JobsHandler) load(w http.ResponseWriter, r *http.Request, req *schema.UserRequest) (jobs.Job, *slog.Logger, bool) {
+	job, err := h.Jobs.Get(r.Context(), r.PathValue("id"))
+	principal, authenticated := auth.FromContext(r.Context())
+	if errors.Is(err, jobs.ErrNotFound) || (err == nil && !job.OwnedBy(principal, authenticated)) {
+		h.API.writeError(w, *req, http.StatusNotFound, jobs.ErrNotFound.Error())
+		return jobs.Job{}, nil, false
+	}
+	if err != nil {
+		h.API.Logger.Error("job load failed", "error", err.Error())
+		h.API.writeError(w, *req, http.StatusInternalServerError, "job load failed")
+		return jobs.Job{}, nil, false
+	}
+	*req = job.Request
+	logger, _ := logging.WithRequest(r.Context(), h.API.Logger, job.Request.RequestID, job.Request.TraceID, "jobs")
+	return job, logger.With("job_id", job.ID), true
+}
+
+func (h *Handler) ExecuteJob(ctx context.Context, job jobs.Job) (result schema.StudentAnalysis, err error) {
+	req := job.Request
+	baseLogger := h.Logger.With("job_id", job.ID)
+	if job.Principal != nil {
+		ctx = auth.WithPrincipal(ctx, *job.Principal)
+		baseLogger = baseLogger.With(slog.String(logging.KeyPrincipal, job.Principal.ID))
+	}
+	if h.Tracer != nil {
+		if traceID, err := tracing.ParseTraceID(req.TraceID); err == nil {
+			ctx = tracing.ContextWithRemote(ctx, tracing.SpanContext{TraceID: traceID})
+		}
+		var span *tracing.Span
+		ctx, span = h.Tracer.Start(ctx, "job "+req.Mode, tracing.SpanKindInternal)
+		span.SetAttribute("job.id", job.ID)
+		defer func() { span.Finish(err) }()
+	}
+	_, ctx = logging.WithRequest(ctx, baseLogger, req.RequestID, req.TraceID, "jobs")
+	result, err = h.Flow.Execute(ctx, req)
+	if err != nil {
+		return schema.StudentAnalysis{}, err
+	}
+	result.RequestID = req.RequestID
+	result.TraceID = req.TraceID
+	return result, nil
+}
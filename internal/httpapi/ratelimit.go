@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"omnibase/internal/auth"
+	"omnibase/internal/ratelimit"
+)
+
+type RateLimiter struct {
+	Policy *ratelimit.Policy
+	Logger *slog.Logger
+}
+
+func NewRateLimiter(policy *ratelimit.Policy, logger *slog.Logger) *RateLimiter {
+	return &RateLimiter{Policy: policy, Logger: logger}
+}
+
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	if l == nil || l.Policy == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := clientKey(r)
+		decision, limited := l.Policy.AllowRoute(client, r.URL.Path)
+		if limited {
+			writeRateLimitHeaders(w, decision)
+		}
+		if limited && !decision.Allowed {
+			l.Logger.Warn("rate limit exceeded", "client", client, "path", r.URL.Path)
+			w.Header().Set("content-type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "rate limit exceeded"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func clientKey(r *http.Request) string {
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return principal.ID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func writeRateLimitHeaders(w http.ResponseWriter, decision ratelimit.Decision) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(decision.Reset.Seconds()))))
+	if !decision.Allowed {
+		setRetryAfter(w, decision.RetryAfter)
+	}
+}
@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"omnibase/internal/auth"
+	"omnibase/internal/schema"
+)
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled
+}
+
+var (
+	ErrNotFound  = errors.New("job not found")
+	ErrQueueFull = errors.New("job queue is full")
+	ErrFinished  = errors.New("job already finished")
+)
+
+type Job struct {
+	ID          string                  `json:"id"`
+	Status      Status                  `json:"status"`
+	Request     schema.UserRequest      `json:"request"`
+	Principal   *auth.Principal         `json:"principal,omitempty"`
+	CallbackURL string                  `json:"callback_url,omitempty"`
+	Result      *schema.StudentAnalysis `json:"result,omitempty"`
+	Error       string                  `json:"error,omitempty"`
+	CreatedAt   time.Time               `json:"created_at"`
+	StartedAt   *time.Time              `json:"started_at,omitempty"`
+	FinishedAt  *time.Time              `json:"finished_at,omitempty"`
+}
+
+func (j Job) OwnedBy(principal auth.Principal, authenticated bool) bool {
+	if j.Principal == nil {
+		return !authenticated
+	}
+	return authenticated && j.Principal.ID == principal.ID
+}
+
+type View struct {
+	ID         string                  `json:"id"`
+	Status     Status                  `json:"status"`
+	RequestID  string                  `json:"request_id"`
+	TraceID    string                  `json:"trace_id"`
+	Mode       string                  `json:"mode"`
+	Result     *schema.StudentAnalysis `json:"result,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+	CreatedAt  time.Time               `json:"created_at"`
+	StartedAt  *time.Time              `json:"started_at,omitempty"`
+	FinishedAt *time.Time              `json:"finished_at,omitempty"`
+}
+
+func (j Job) View() View {
+	return View{
+		ID:         j.ID,
+		Status:     j.Status,
+		RequestID:  j.Request.RequestID,
+		TraceID:    j.Request.TraceID,
+		Mode:       j.Request.Mode,
+		Result:     j.Result,
+		Error:      j.Error,
+		CreatedAt:  j.CreatedAt,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+	}
+}
+
+type Store interface {
+	Save(ctx context.Context, job Job) error
+	Get(ctx context.Context, id string) (Job, error)
+	Delete(ctx context.Context, id string) error
+	ListUnfinished(ctx context.Context) ([]Job, error)
+	DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+func newJobID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return "job_" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,288 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"omnibase/internal/schema"
+)
+
+type Executor func(ctx context.Context, job Job) (schema.StudentAnalysis, error)
+
+type Config struct {
+	Workers   int
+	QueueSize int
+	Timeout   time.Duration
+	Retention time.Duration
+}
+
+type Manager struct {
+	cfg      Config
+	store    Store
+	execute  Executor
+	notifier *Notifier
+	logger   *slog.Logger
+
+	queue  chan string
+	ctx    context.Context
+	stop   context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	active map[string]*activeJob
+}
+
+type activeJob struct {
+	cancel   context.CancelFunc
+	canceled bool
+}
+
+func NewManager(cfg Config, store Store, execute Executor, notifier *Notifier, logger *slog.Logger) *Manager {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 2
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Minute
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * time.Hour
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	return &Manager{
+		cfg:      cfg,
+		store:    store,
+		execute:  execute,
+		notifier: notifier,
+		logger:   logger,
+		queue:    make(chan string, cfg.QueueSize),
+		ctx:      ctx,
+		stop:     stop,
+		active:   make(map[string]*activeJob),
+	}
+}
+
+func (m *Manager) Start(ctx context.Context) error {
+	pending, err := m.store.ListUnfinished(ctx)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < m.cfg.Workers; i++ {
+		m.wg.Add(1)
+		go m.work()
+	}
+	m.wg.Add(1)
+	go m.cleanup()
+	if len(pending) > 0 {
+		m.logger.Info("resuming unfinished jobs", "job_count", len(pending))
+		m.wg.Add(1)
+		go m.resume(pending)
+	}
+	return nil
+}
+
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.stop()
+	finished := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) Submit(ctx context.Context, job Job) (Job, error) {
+	job.ID = newJobID()
+	job.Status = StatusQueued
+	job.CreatedAt = time.Now().UTC()
+	if err := m.store.Save(ctx, job); err != nil {
+		return Job{}, err
+	}
+	select {
+	case m.queue <- job.ID:
+		return job, nil
+	default:
+		if err := m.store.Delete(ctx, job.ID); err != nil {
+			m.logger.Error("discard rejected job failed", "job_id", job.ID, "error", err.Error())
+		}
+		return Job{}, ErrQueueFull
+	}
+}
+
+func (m *Manager) Get(ctx context.Context, id string) (Job, error) {
+	return m.store.Get(ctx, id)
+}
+
+func (m *Manager) Cancel(ctx context.Context, id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, err := m.store.Get(ctx, id)
+	if err != nil {
+		return Job{}, err
+	}
+	switch job.Status {
+	case StatusQueued:
+		m.finish(&job, StatusCanceled, nil, "canceled before start")
+		if err := m.store.Save(ctx, job); err != nil {
+			return Job{}, err
+		}
+		m.notify(job)
+		return job, nil
+	case StatusRunning:
+		if active, ok := m.active[id]; ok {
+			active.canceled = true
+			active.cancel()
+		}
+		return job, nil
+	default:
+		return job, ErrFinished
+	}
+}
+
+func (m *Manager) resume(pending []Job) {
+	defer m.wg.Done()
+	for _, job := range pending {
+		if job.Status == StatusRunning {
+			job.Status = StatusQueued
+			job.StartedAt = nil
+			if err := m.store.Save(m.ctx, job); err != nil {
+				m.logger.Error("requeue job failed", "job_id", job.ID, "error", err.Error())
+				continue
+			}
+		}
+		select {
+		case m.queue <- job.ID:
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Manager) work() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case id := <-m.queue:
+			m.run(id)
+		}
+	}
+}
+
+func (m *Manager) run(id string) {
+	job, jobCtx, ok := m.begin(id)
+	if !ok {
+		return
+	}
+	logger := m.logger.With("job_id", job.ID, "request_id", job.Request.RequestID)
+	logger.Info("job started")
+	result, err := m.execute(jobCtx, job)
+
+	m.mu.Lock()
+	active := m.active[id]
+	delete(m.active, id)
+	active.cancel()
+	switch {
+	case err == nil:
+		m.finish(&job, StatusSucceeded, &result, "")
+	case active.canceled:
+		m.finish(&job, StatusCanceled, nil, "canceled while running")
+	case m.ctx.Err() != nil:
+		job.Status = StatusQueued
+		job.StartedAt = nil
+	case errors.Is(err, context.DeadlineExceeded):
+		m.finish(&job, StatusFailed, nil, "job timed out")
+	default:
+		m.finish(&job, StatusFailed, nil, err.Error())
+	}
+	saveErr := m.store.Save(context.Background(), job)
+	m.mu.Unlock()
+
+	if saveErr != nil {
+		logger.Error("save job failed", "error", saveErr.Error())
+		return
+	}
+	if !job.Status.Finished() {
+		logger.Info("job interrupted by shutdown")
+		return
+	}
+	logger.Info("job finished", "status", string(job.Status))
+	m.notify(job)
+}
+
+func (m *Manager) begin(id string) (Job, context.Context, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, err := m.store.Get(m.ctx, id)
+	if err != nil {
+		m.logger.Error("load job failed", "job_id", id, "error", err.Error())
+		return Job{}, nil, false
+	}
+	if job.Status != StatusQueued {
+		return Job{}, nil, false
+	}
+	now := time.Now().UTC()
+	job.Status = StatusRunning
+	job.StartedAt = &now
+	if err := m.store.Save(m.ctx, job); err != nil {
+		m.logger.Error("save job failed", "job_id", id, "error", err.Error())
+		return Job{}, nil, false
+	}
+	jobCtx, cancel := context.WithTimeout(m.ctx, m.cfg.Timeout)
+	m.active[id] = &activeJob{cancel: cancel}
+	return job, jobCtx, true
+}
+
+func (m *Manager) finish(job *Job, status Status, result *schema.StudentAnalysis, message string) {
+	now := time.Now().UTC()
+	job.Status = status
+	job.Result = result
+	job.Error = message
+	job.FinishedAt = &now
+}
+
+func (m *Manager) notify(job Job) {
+	if job.CallbackURL == "" || m.notifier == nil {
+		return
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := m.notifier.Notify(ctx, job.CallbackURL, job.View()); err != nil {
+			m.logger.Warn("job webhook failed", "job_id", job.ID, "error", err.Error())
+		}
+	}()
+}
+
+func (m *Manager) cleanup() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := m.store.DeleteFinishedBefore(m.ctx, time.Now().Add(-m.cfg.Retention))
+			if err != nil {
+				m.logger.Error("job cleanup failed", "error", err.Error())
+				continue
+			}
+			if deleted > 0 {
+				m.logger.Info("expired jobs removed", "job_count", deleted)
+			}
+		}
+	}
+}
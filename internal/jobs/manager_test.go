@@ -0,0 +1,204 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"omnibase/internal/schema"
+)
+
+func newTestManager(t *testing.T, cfg Config, store Store, execute Executor) *Manager {
+	t.Helper()
+	m := NewManager(cfg, store, execute, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = m.Shutdown(ctx)
+	})
+	return m
+}
+
+func waitForStatus(t *testing.T, m *Manager, id string, want Status) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := m.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", id, err)
+		}
+		if job.Status == want {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s status = %s, want %s", id, job.Status, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func blockingExecutor(started chan<- string) Executor {
+	return func(ctx context.Context, job Job) (schema.StudentAnalysis, error) {
+		started <- job.ID
+		<-ctx.Done()
+		return schema.StudentAnalysis{}, ctx.Err()
+	}
+}
+
+func TestManagerRunsJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		execute Executor
+		timeout time.Duration
+		status  Status
+		message string
+	}{
+		{"succeeded", func(context.Context, Job) (schema.StudentAnalysis, error) {
+			return schema.StudentAnalysis{Summary: "ok"}, nil
+		}, time.Minute, StatusSucceeded, ""},
+		{"failed", func(context.Context, Job) (schema.StudentAnalysis, error) {
+			return schema.StudentAnalysis{}, errors.New("flow failed")
+		}, time.Minute, StatusFailed, "flow failed"},
+		{"timed out", func(ctx context.Context, _ Job) (schema.StudentAnalysis, error) {
+			<-ctx.Done()
+			return schema.StudentAnalysis{}, ctx.Err()
+		}, 10 * time.Millisecond, StatusFailed, "job timed out"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t, Config{Workers: 1, Timeout: tt.timeout}, NewMemoryStore(), tt.execute)
+			job, err := m.Submit(context.Background(), Job{})
+			if err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+			job = waitForStatus(t, m, job.ID, tt.status)
+			if job.Error != tt.message {
+				t.Errorf("Error = %q, want %q", job.Error, tt.message)
+			}
+			if job.FinishedAt == nil || job.StartedAt == nil {
+				t.Errorf("timestamps not set: started %v finished %v", job.StartedAt, job.FinishedAt)
+			}
+			if tt.status == StatusSucceeded && (job.Result == nil || job.Result.Summary != "ok") {
+				t.Errorf("Result = %+v", job.Result)
+			}
+		})
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	started := make(chan string, 1)
+	m := newTestManager(t, Config{Workers: 1, Timeout: time.Minute}, NewMemoryStore(), blockingExecutor(started))
+	running, err := m.Submit(context.Background(), Job{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	queued, err := m.Submit(context.Background(), Job{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := m.Cancel(context.Background(), queued.ID)
+	if err != nil || job.Status != StatusCanceled || job.Error != "canceled before start" {
+		t.Fatalf("Cancel(queued) = %+v, %v", job, err)
+	}
+	if _, err := m.Cancel(context.Background(), running.ID); err != nil {
+		t.Fatalf("Cancel(running) error = %v", err)
+	}
+	job = waitForStatus(t, m, running.ID, StatusCanceled)
+	if job.Error != "canceled while running" {
+		t.Errorf("Error = %q", job.Error)
+	}
+	if _, err := m.Cancel(context.Background(), running.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("Cancel(finished) error = %v, want ErrFinished", err)
+	}
+	if _, err := m.Cancel(context.Background(), "job_missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestManagerRejectsWhenQueueIsFull(t *testing.T) {
+	started := make(chan string, 1)
+	store := NewMemoryStore()
+	m := newTestManager(t, Config{Workers: 1, QueueSize: 1, Timeout: time.Minute}, store, blockingExecutor(started))
+	if _, err := m.Submit(context.Background(), Job{}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if _, err := m.Submit(context.Background(), Job{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Submit(context.Background(), Job{}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Submit() error = %v, want ErrQueueFull", err)
+	}
+	unfinished, _ := store.ListUnfinished(context.Background())
+	if len(unfinished) != 2 {
+		t.Errorf("stored unfinished jobs = %d, want 2", len(unfinished))
+	}
+}
+
+func TestManagerRequeuesUnfinishedJobsOnStart(t *testing.T) {
+	store := NewMemoryStore()
+	startedAt := time.Now().UTC()
+	for _, job := range []Job{
+		{ID: "job_running", Status: StatusRunning, StartedAt: &startedAt},
+		{ID: "job_queued", Status: StatusQueued},
+		{ID: "job_done", Status: StatusSucceeded, FinishedAt: &startedAt},
+	} {
+		if err := store.Save(context.Background(), job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	executed := make(chan string, 3)
+	m := newTestManager(t, Config{Workers: 2, Timeout: time.Minute}, store, func(_ context.Context, job Job) (schema.StudentAnalysis, error) {
+		executed <- job.ID
+		return schema.StudentAnalysis{}, nil
+	})
+	waitForStatus(t, m, "job_running", StatusSucceeded)
+	waitForStatus(t, m, "job_queued", StatusSucceeded)
+	close(executed)
+	ran := map[string]bool{}
+	for id := range executed {
+		ran[id] = true
+	}
+	if len(ran) != 2 || ran["job_done"] {
+		t.Errorf("executed jobs = %v", ran)
+	}
+}
+
+func TestMemoryStoreDeleteFinishedBefore(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	old, recent := now.Add(-48*time.Hour), now.Add(-time.Hour)
+	for _, job := range []Job{
+		{ID: "old", Status: StatusSucceeded, FinishedAt: &old},
+		{ID: "old_failed", Status: StatusFailed, FinishedAt: &old},
+		{ID: "recent", Status: StatusCanceled, FinishedAt: &recent},
+		{ID: "queued", Status: StatusQueued},
+	} {
+		_ = store.Save(context.Background(), job)
+	}
+	deleted, err := store.DeleteFinishedBefore(context.Background(), now.Add(-24*time.Hour))
+	if err != nil || deleted != 2 {
+		t.Fatalf("DeleteFinishedBefore() = %d, %v, want 2", deleted, err)
+	}
+	for id, want := range map[string]bool{"old": false, "old_failed": false, "recent": true, "queued": true} {
+		if _, err := store.Get(context.Background(), id); (err == nil) != want {
+			t.Errorf("Get(%s) error = %v, want present %v", id, err, want)
+		}
+	}
+}
+
+func TestNewSQLStoreRejectsUnsupportedDrivers(t *testing.T) {
+	for _, driver := range []string{"sqlite", "sqlite3", "postgres"} {
+		if _, err := NewSQLStore(context.Background(), driver, "jobs.db"); err == nil {
+			t.Errorf("NewSQLStore(%q) accepted an unsupported driver", driver)
+		}
+	}
+}
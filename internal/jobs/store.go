@@ -0,0 +1,187 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type MemoryStore struct {
+	mu   sync.RWMutex
+	jobs map[string]Job
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]Job)}
+}
+
+func (s *MemoryStore) Save(ctx context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return job, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+func (s *MemoryStore) ListUnfinished(ctx context.Context) ([]Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jobs := make([]Job, 0)
+	for _, job := range s.jobs {
+		if !job.Status.Finished() {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (s *MemoryStore) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := 0
+	for id, job := range s.jobs {
+		if job.Status.Finished() && job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(s.jobs, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type SQLStore struct {
+	db *sql.DB
+}
+
+const (
+	DriverMySQL = "mysql"
+
+	mysqlSchema = `create table if not exists omnibase_jobs (
+		id varchar(64) primary key,
+		status varchar(16) not null,
+		data mediumtext not null,
+		finished_at bigint
+	)`
+	mysqlUpsert = `insert into omnibase_jobs (id, status, data, finished_at) values (?, ?, ?, ?)
+		on duplicate key update status = values(status), data = values(data), finished_at = values(finished_at)`
+)
+
+func NewSQLStore(ctx context.Context, driver, dsn string) (*SQLStore, error) {
+	if driver == "" || dsn == "" {
+		return nil, errors.New("driver and dsn are required")
+	}
+	if driver != DriverMySQL {
+		return nil, fmt.Errorf("unsupported job store driver %q: only %s is supported", driver, DriverMySQL)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open job store: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, mysqlSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create job table: %w", err)
+	}
+	return &SQLStore{db: db}, nil
+}
+
+func (s *SQLStore) Close() error {
+	if s == nil || s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+func (s *SQLStore) Save(ctx context.Context, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encode job: %w", err)
+	}
+	var finishedAt any
+	if job.FinishedAt != nil {
+		finishedAt = job.FinishedAt.Unix()
+	}
+	_, err = s.db.ExecContext(ctx, mysqlUpsert, job.ID, string(job.Status), string(data), finishedAt)
+	if err != nil {
+		return fmt.Errorf("save job: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLStore) Get(ctx context.Context, id string) (Job, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, "select data from omnibase_jobs where id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Job{}, ErrNotFound
+	}
+	if err != nil {
+		return Job{}, fmt.Errorf("load job: %w", err)
+	}
+	return decodeJob(data)
+}
+
+func (s *SQLStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, "delete from omnibase_jobs where id = ?", id); err != nil {
+		return fmt.Errorf("delete job: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLStore) ListUnfinished(ctx context.Context) ([]Job, error) {
+	rows, err := s.db.QueryContext(ctx, "select data from omnibase_jobs where status in (?, ?)", string(StatusQueued), string(StatusRunning))
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	defer rows.Close()
+	jobs := make([]Job, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		job, err := decodeJob(data)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (s *SQLStore) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, "delete from omnibase_jobs where finished_at is not null and finished_at < ?", cutoff.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("delete finished jobs: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return int(deleted), nil
+}
+
+func decodeJob(data string) (Job, error) {
+	var job Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return Job{}, fmt.Errorf("decode job: %w", err)
+	}
+	return job, nil
+}
@@ -0,0 +1,143 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-OmniBase-Signature"
+	HeaderTimestamp = "X-OmniBase-Timestamp"
+)
+
+type Notifier struct {
+	secret       []byte
+	allowedHosts []string
+	attempts     int
+	client       *http.Client
+}
+
+var errBlockedAddress = errors.New("callback address is not publicly routable")
+
+func NewNotifier(secret string, allowedHosts []string) *Notifier {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if len(allowedHosts) == 0 {
+		dialer.Control = blockInternalAddresses
+	}
+	return &Notifier{
+		secret:       []byte(secret),
+		allowedHosts: allowedHosts,
+		attempts:     3,
+		client: &http.Client{
+			Timeout:       10 * time.Second,
+			Transport:     &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 5 * time.Second},
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+func blockInternalAddresses(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errBlockedAddress, address)
+	}
+	if internalAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", errBlockedAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+func internalAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified()
+}
+
+func (n *Notifier) ValidateURL(raw string) error {
+	if n == nil || len(n.secret) == 0 {
+		return errors.New("webhooks are not configured")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || !parsed.IsAbs() || parsed.Host == "" {
+		return errors.New("callback_url must be an absolute URL")
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return errors.New("callback_url must use http or https")
+	}
+	if len(n.allowedHosts) > 0 {
+		if !slices.Contains(n.allowedHosts, parsed.Hostname()) {
+			return fmt.Errorf("callback_url host %s is not allowed", parsed.Hostname())
+		}
+		return nil
+	}
+	if addr, err := netip.ParseAddr(parsed.Hostname()); err == nil && internalAddress(addr) {
+		return fmt.Errorf("callback_url host %s is not publicly routable", parsed.Hostname())
+	}
+	if parsed.Hostname() == "localhost" {
+		return errors.New("callback_url host localhost is not publicly routable")
+	}
+	return nil
+}
+
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (n *Notifier) Notify(ctx context.Context, callbackURL string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+	var lastErr error
+	for attempt := 0; attempt < n.attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * 2 * time.Second):
+			}
+		}
+		if lastErr = n.send(ctx, callbackURL, body); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+func (n *Notifier) send(ctx context.Context, callbackURL string, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(n.secret, timestamp, body))
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
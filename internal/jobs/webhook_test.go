@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(`1700000000.{"id":"job_1"}`))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := Sign([]byte("secret"), "1700000000", []byte(`{"id":"job_1"}`)); got != want {
+		t.Fatalf("Sign() = %s, want %s", got, want)
+	}
+	if Sign([]byte("secret"), "1700000001", []byte(`{"id":"job_1"}`)) == want {
+		t.Error("signature does not cover the timestamp")
+	}
+}
+
+func TestNotifierValidateURL(t *testing.T) {
+	open := NewNotifier("secret", nil)
+	allowlisted := NewNotifier("secret", []string{"hooks.example.com"})
+	tests := []struct {
+		name     string
+		notifier *Notifier
+		url      string
+		wantErr  bool
+	}{
+		{"public https", open, "https://hooks.example.com/omnibase", false},
+		{"public http", open, "http://93.184.216.34/hook", false},
+		{"relative", open, "/hook", true},
+		{"bad scheme", open, "ftp://hooks.example.com/hook", true},
+		{"loopback", open, "http://127.0.0.1:8080/hook", true},
+		{"loopback v6", open, "http://[::1]/hook", true},
+		{"mapped loopback", open, "http://[::ffff:127.0.0.1]/hook", true},
+		{"private", open, "http://10.0.0.5/hook", true},
+		{"link local metadata", open, "http://169.254.169.254/latest", true},
+		{"unspecified", open, "http://0.0.0.0/hook", true},
+		{"localhost", open, "http://localhost/hook", true},
+		{"allowlisted host", allowlisted, "https://hooks.example.com/hook", false},
+		{"host not allowlisted", allowlisted, "https://other.example.com/hook", true},
+		{"no secret", NewNotifier("", nil), "https://hooks.example.com/hook", true},
+		{"nil notifier", nil, "https://hooks.example.com/hook", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.notifier.ValidateURL(tt.url); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateURL(%s) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotifierSignsDeliveries(t *testing.T) {
+	var body []byte
+	var timestamp, signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		timestamp, signature = r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature)
+	}))
+	defer server.Close()
+	host, _ := url.Parse(server.URL)
+
+	notifier := NewNotifier("secret", []string{host.Hostname()})
+	notifier.attempts = 1
+	if err := notifier.Notify(context.Background(), server.URL, map[string]string{"id": "job_1"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if string(body) != `{"id":"job_1"}` {
+		t.Errorf("body = %s", body)
+	}
+	if timestamp == "" || signature != Sign([]byte("secret"), timestamp, body) {
+		t.Errorf("signature %q does not match timestamp %q", signature, timestamp)
+	}
+}
+
+func TestNotifierBlocksInternalAddressesAtDial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("request reached an internal address")
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier("secret", nil)
+	notifier.attempts = 1
+	err := notifier.Notify(context.Background(), server.URL, map[string]string{"id": "job_1"})
+	if !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("Notify() error = %v, want errBlockedAddress", err)
+	}
+}
@@ -0,0 +1,171 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"omnibase/internal/breaker"
+	"omnibase/internal/tracing"
+)
+
+const (
+	DefaultEmbeddingBatchSize   = 64
+	DefaultEmbeddingBatchTokens = 8192
+	DefaultEmbeddingParallelism = 4
+)
+
+type EmbeddingBatchRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type batchLimits struct {
+	size        int
+	tokens      int
+	parallelism int
+}
+
+func (c *Client) WithBatching(size, tokens, parallelism int) *Client {
+	c.batch = batchLimits{size: size, tokens: tokens, parallelism: parallelism}
+	return c
+}
+
+func (c *Client) EmbedBatch(ctx context.Context, inputs []string) (vectors [][]float32, err error) {
+	ctx, span := tracing.Start(ctx, "llm.embeddings_batch", tracing.SpanKindClient)
+	defer func() { span.Finish(err) }()
+	span.SetAttribute("llm.model", c.model)
+	span.SetAttribute("llm.embedding_inputs", len(inputs))
+
+	batches := c.splitBatches(inputs)
+	span.SetAttribute("llm.embedding_batches", len(batches))
+	vectors = make([][]float32, len(inputs))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	parallelism := c.batch.parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultEmbeddingParallelism
+	}
+	semaphore := make(chan struct{}, parallelism)
+	errs := make([]error, len(batches))
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			start := time.Now()
+			var embedded [][]float32
+			errs[i] = c.breaker.Do(func() error {
+				var err error
+				embedded, err = c.embedBatch(ctx, inputs[batch.start:batch.end])
+				return err
+			})
+			c.metrics.ObserveEmbedding(time.Since(start), errs[i])
+			if errs[i] != nil {
+				cancel()
+				return
+			}
+			copy(vectors[batch.start:batch.end], embedded)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+type batchRange struct {
+	start int
+	end   int
+}
+
+func (c *Client) splitBatches(inputs []string) []batchRange {
+	size, tokens := c.batch.size, c.batch.tokens
+	if size <= 0 {
+		size = DefaultEmbeddingBatchSize
+	}
+	if tokens <= 0 {
+		tokens = DefaultEmbeddingBatchTokens
+	}
+	var batches []batchRange
+	start, budget := 0, 0
+	for i, input := range inputs {
+		estimate := estimateTokens(input)
+		if i > start && (i-start >= size || budget+estimate > tokens) {
+			batches = append(batches, batchRange{start: start, end: i})
+			start, budget = i, 0
+		}
+		budget += estimate
+	}
+	if start < len(inputs) {
+		batches = append(batches, batchRange{start: start, end: len(inputs)})
+	}
+	return batches
+}
+
+func estimateTokens(text string) int {
+	return utf8.RuneCountInString(text)/4 + 1
+}
+
+func (c *Client) embedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	endpoint := fmt.Sprintf("%s/v1/embeddings", c.baseURL)
+	payload := EmbeddingBatchRequest{Model: c.model, Input: inputs}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create embedding request: %w", err)
+	}
+	c.setHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send embedding request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, &breaker.StatusError{Op: "embedding", StatusCode: resp.StatusCode}
+	}
+
+	var decoded EmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("decode embedding response: %w", err)
+	}
+	if len(decoded.Data) != len(inputs) {
+		return nil, fmt.Errorf("embedding response has %d items, expected %d", len(decoded.Data), len(inputs))
+	}
+	vectors := make([][]float32, len(inputs))
+	for _, item := range decoded.Data {
+		if item.Index < 0 || item.Index >= len(inputs) || vectors[item.Index] != nil {
+			return nil, fmt.Errorf("embedding response has invalid index %d", item.Index)
+		}
+		if c.dimensions > 0 && len(item.Embedding) != c.dimensions {
+			return nil, &DimensionError{Got: len(item.Embedding), Want: c.dimensions}
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
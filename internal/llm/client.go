@@ -6,21 +6,71 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
+
+	"omnibase/internal/admission"
+	"omnibase/internal/breaker"
+	"omnibase/internal/metrics"
+	"omnibase/internal/tracing"
 )
 
 type Client struct {
-	baseURL string
-	model   string
-	client  *http.Client
+	baseURL    string
+	model      string
+	apiKey     string
+	dimensions int
+	client     *http.Client
+	breaker    *breaker.Breaker
+	limiter    *admission.Limiter
+	metrics    *metrics.Metrics
+	batch      batchLimits
 }
 
 func NewClient(baseURL, model string) *Client {
-	return &Client{baseURL: baseURL, model: model, client: &http.Client{}}
+	return &Client{baseURL: baseURL, model: model, client: &http.Client{Transport: tracing.NewTransport(nil)}}
+}
+
+func (c *Client) WithAPIKey(apiKey string) *Client {
+	c.apiKey = apiKey
+	return c
+}
+
+func (c *Client) WithDimensions(dimensions int) *Client {
+	c.dimensions = dimensions
+	return c
+}
+
+func (c *Client) WithBreaker(b *breaker.Breaker) *Client {
+	c.breaker = b
+	return c
+}
+
+func (c *Client) WithLimiter(l *admission.Limiter) *Client {
+	c.limiter = l
+	return c
+}
+
+func (c *Client) WithMetrics(m *metrics.Metrics) *Client {
+	c.metrics = m
+	return c
 }
 
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+type ToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function ToolCallFunction `json:"function"`
+}
+
+type ToolCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 type Tool struct {
@@ -44,6 +94,13 @@ type ChatCompletionResponse struct {
 	Choices []struct {
 		Message Message `json:"message"`
 	} `json:"choices"`
+	Usage Usage `json:"usage"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 type EmbeddingRequest struct {
@@ -53,44 +110,99 @@ type EmbeddingRequest struct {
 
 type EmbeddingResponse struct {
 	Data []struct {
+		Index     int       `json:"index"`
 		Embedding []float32 `json:"embedding"`
 	} `json:"data"`
 }
 
+type DimensionError struct {
+	Got  int
+	Want int
+}
+
+func (e *DimensionError) Error() string {
+	return fmt.Sprintf("embedding has %d dimensions, expected %d", e.Got, e.Want)
+}
+
 func (c *Client) ChatCompletion(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	message, err := c.Complete(ctx, messages, tools)
+	return message.Content, err
+}
+
+func (c *Client) Complete(ctx context.Context, messages []Message, tools []Tool) (message Message, err error) {
+	ctx, span := tracing.Start(ctx, "llm.chat_completion", tracing.SpanKindClient)
+	defer func() { span.Finish(err) }()
+	span.SetAttribute("llm.model", c.model)
+
+	release, err := c.limiter.Acquire(ctx, 1)
+	if err != nil {
+		return Message{}, err
+	}
+	defer release()
+
+	start := time.Now()
+	var usage Usage
+	err = c.breaker.Do(func() error {
+		var err error
+		message, usage, err = c.chatCompletion(ctx, messages, tools)
+		return err
+	})
+	c.metrics.ObserveLLMCompletion(time.Since(start), usage.PromptTokens, usage.CompletionTokens, err)
+	span.SetAttribute("llm.prompt_tokens", usage.PromptTokens)
+	span.SetAttribute("llm.completion_tokens", usage.CompletionTokens)
+	span.SetAttribute("llm.tool_calls", len(message.ToolCalls))
+	return message, err
+}
+
+func (c *Client) chatCompletion(ctx context.Context, messages []Message, tools []Tool) (Message, Usage, error) {
 	endpoint := fmt.Sprintf("%s/v1/chat/completions", c.baseURL)
 	payload := ChatCompletionRequest{Model: c.model, Messages: messages, Tools: tools}
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("encode chat completion request: %w", err)
+		return Message{}, Usage{}, fmt.Errorf("encode chat completion request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
 	if err != nil {
-		return "", fmt.Errorf("create chat completion request: %w", err)
+		return Message{}, Usage{}, fmt.Errorf("create chat completion request: %w", err)
 	}
-	req.Header.Set("content-type", "application/json")
+	c.setHeaders(req)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("send chat completion request: %w", err)
+		return Message{}, Usage{}, fmt.Errorf("send chat completion request: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
-		return "", fmt.Errorf("chat completion failed: status %d", resp.StatusCode)
+		return Message{}, Usage{}, &breaker.StatusError{Op: "chat completion", StatusCode: resp.StatusCode}
 	}
 
 	var decoded ChatCompletionResponse
 	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
-		return "", fmt.Errorf("decode chat completion response: %w", err)
+		return Message{}, Usage{}, fmt.Errorf("decode chat completion response: %w", err)
 	}
 	if len(decoded.Choices) == 0 {
-		return "", fmt.Errorf("chat completion response missing choices")
+		return Message{}, Usage{}, fmt.Errorf("chat completion response missing choices")
 	}
-	return decoded.Choices[0].Message.Content, nil
+	return decoded.Choices[0].Message, decoded.Usage, nil
+}
+
+func (c *Client) Embed(ctx context.Context, input string) (vector []float32, err error) {
+	ctx, span := tracing.Start(ctx, "llm.embeddings", tracing.SpanKindClient)
+	defer func() { span.Finish(err) }()
+	span.SetAttribute("llm.model", c.model)
+
+	start := time.Now()
+	err = c.breaker.Do(func() error {
+		var err error
+		vector, err = c.embed(ctx, input)
+		return err
+	})
+	c.metrics.ObserveEmbedding(time.Since(start), err)
+	return vector, err
 }
 
-func (c *Client) Embed(ctx context.Context, input string) ([]float32, error) {
+func (c *Client) embed(ctx context.Context, input string) ([]float32, error) {
 	endpoint := fmt.Sprintf("%s/v1/embeddings", c.baseURL)
 	payload := EmbeddingRequest{Model: c.model, Input: input}
 	body, err := json.Marshal(payload)
@@ -102,7 +214,7 @@ func (c *Client) Embed(ctx context.Context, input string) ([]float32, error) {
 	if err != nil {
 		return nil, fmt.Errorf("create embedding request: %w", err)
 	}
-	req.Header.Set("content-type", "application/json")
+	c.setHeaders(req)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -110,7 +222,7 @@ func (c *Client) Embed(ctx context.Context, input string) ([]float32, error) {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("embedding failed: status %d", resp.StatusCode)
+		return nil, &breaker.StatusError{Op: "embedding", StatusCode: resp.StatusCode}
 	}
 
 	var decoded EmbeddingResponse
@@ -120,5 +232,15 @@ func (c *Client) Embed(ctx context.Context, input string) ([]float32, error) {
 	if len(decoded.Data) == 0 {
 		return nil, fmt.Errorf("embedding response missing data")
 	}
+	if c.dimensions > 0 && len(decoded.Data[0].Embedding) != c.dimensions {
+		return nil, &DimensionError{Got: len(decoded.Data[0].Embedding), Want: c.dimensions}
+	}
 	return decoded.Data[0].Embedding, nil
 }
+
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("content-type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("authorization", "Bearer "+c.apiKey)
+	}
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"omnibase/internal/breaker"
+	"omnibase/internal/tracing"
+)
+
+type RerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type RerankResult struct {
+	Index          int     `json:"index"`
+	RelevanceScore float64 `json:"relevance_score"`
+}
+
+type RerankResponse struct {
+	Results []RerankResult `json:"results"`
+}
+
+func (c *Client) Rerank(ctx context.Context, query string, documents []string, topN int) (results []RerankResult, err error) {
+	ctx, span := tracing.Start(ctx, "llm.rerank", tracing.SpanKindClient)
+	defer func() { span.Finish(err) }()
+	span.SetAttribute("llm.model", c.model)
+	span.SetAttribute("llm.rerank_documents", len(documents))
+
+	err = c.breaker.Do(func() error {
+		var err error
+		results, err = c.rerank(ctx, query, documents, topN)
+		return err
+	})
+	return results, err
+}
+
+func (c *Client) rerank(ctx context.Context, query string, documents []string, topN int) ([]RerankResult, error) {
+	endpoint := fmt.Sprintf("%s/v1/rerank", c.baseURL)
+	payload := RerankRequest{Model: c.model, Query: query, Documents: documents, TopN: topN}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode rerank request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create rerank request: %w", err)
+	}
+	c.setHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send rerank request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, &breaker.StatusError{Op: "rerank", StatusCode: resp.StatusCode}
+	}
+
+	var decoded RerankResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("decode rerank response: %w", err)
+	}
+	for _, result := range decoded.Results {
+		if result.Index < 0 || result.Index >= len(documents) {
+			return nil, fmt.Errorf("rerank result index %d out of range", result.Index)
+		}
+	}
+	return decoded.Results, nil
+}
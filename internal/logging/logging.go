@@ -10,6 +10,7 @@ const (
 	KeyRequestID = "request_id"
 	KeyTraceID   = "trace_id"
 	KeyComponent = "component"
+	KeyPrincipal = "principal"
 )
 
 func NewLogger() *slog.Logger {
@@ -17,16 +18,31 @@ func NewLogger() *slog.Logger {
 }
 
 func WithRequest(ctx context.Context, logger *slog.Logger, requestID, traceID, component string) (*slog.Logger, context.Context) {
-	logger = logger.With(
+	base := logger.With(
 		slog.String(KeyRequestID, requestID),
 		slog.String(KeyTraceID, traceID),
-		slog.String(KeyComponent, component),
 	)
+	ctx = context.WithValue(ctx, baseKey{}, base)
+	logger = base.With(slog.String(KeyComponent, component))
+	return logger, context.WithValue(ctx, loggerKey{}, logger)
+}
+
+func WithComponent(ctx context.Context, fallback *slog.Logger, component string) (*slog.Logger, context.Context) {
+	base, ok := ctx.Value(baseKey{}).(*slog.Logger)
+	if !ok {
+		base = fallback
+	}
+	if base == nil {
+		base = slog.Default()
+	}
+	logger := base.With(slog.String(KeyComponent, component))
 	return logger, context.WithValue(ctx, loggerKey{}, logger)
 }
 
 type loggerKey struct{}
 
+type baseKey struct{}
+
 func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
 	if ctx == nil {
 		return fallback
@@ -36,3 +52,13 @@ func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
 	}
 	return fallback
 }
+
+func With(ctx context.Context, args ...any) context.Context {
+	if base, ok := ctx.Value(baseKey{}).(*slog.Logger); ok {
+		ctx = context.WithValue(ctx, baseKey{}, base.With(args...))
+	}
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		ctx = context.WithValue(ctx, loggerKey{}, logger.With(args...))
+	}
+	return ctx
+}
@@ -6,16 +6,42 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"omnibase/internal/analytics"
+	"omnibase/internal/breaker"
+	"omnibase/internal/metrics"
+	"omnibase/internal/schema"
+	"omnibase/internal/tracing"
 )
 
 type Client struct {
 	baseURL  string
 	tools    map[string]Tool
+	internal map[string]Tool
 	client   *http.Client
 	executor *SQLExecutor
+	breaker  *breaker.Breaker
+	metrics  *metrics.Metrics
 }
 
 func NewClient(baseURL string, tools []Tool, executor *SQLExecutor) (*Client, error) {
+	toolMap, err := toolsByName(tools)
+	if err != nil {
+		return nil, err
+	}
+	internal, err := toolsByName(InternalTools())
+	if err != nil {
+		return nil, err
+	}
+	for name := range internal {
+		if _, ok := toolMap[name]; ok {
+			return nil, fmt.Errorf("tool %s is internal and cannot be offered to the model", name)
+		}
+	}
+	return &Client{baseURL: baseURL, tools: toolMap, internal: internal, client: &http.Client{Transport: tracing.NewTransport(nil)}, executor: executor}, nil
+}
+
+func toolsByName(tools []Tool) (map[string]Tool, error) {
 	toolMap := make(map[string]Tool, len(tools))
 	for _, tool := range tools {
 		if err := tool.Validate(); err != nil {
@@ -23,7 +49,17 @@ func NewClient(baseURL string, tools []Tool, executor *SQLExecutor) (*Client, er
 		}
 		toolMap[tool.Name] = tool
 	}
-	return &Client{baseURL: baseURL, tools: toolMap, client: &http.Client{}, executor: executor}, nil
+	return toolMap, nil
+}
+
+func (c *Client) WithBreaker(b *breaker.Breaker) *Client {
+	c.breaker = b
+	return c
+}
+
+func (c *Client) WithMetrics(m *metrics.Metrics) *Client {
+	c.metrics = m
+	return c
 }
 
 type ToolRequest struct {
@@ -39,18 +75,59 @@ type ToolResponse struct {
 func (c *Client) Dispatch(ctx context.Context, toolName string, args map[string]any) (map[string]any, error) {
 	tool, ok := c.tools[toolName]
 	if !ok {
-		return nil, fmt.Errorf("unknown tool: %s", toolName)
+		err := fmt.Errorf("unknown tool: %s", toolName)
+		c.metrics.ObserveMCPDispatch(toolName, err)
+		return nil, err
 	}
+	return c.dispatch(ctx, tool, args)
+}
+
+func (c *Client) dispatch(ctx context.Context, tool Tool, args map[string]any) (data map[string]any, err error) {
+	toolName := tool.Name
+	ctx, span := tracing.Start(ctx, "mcp.dispatch", tracing.SpanKindClient)
+	defer func() {
+		c.metrics.ObserveMCPDispatch(toolName, err)
+		span.Finish(err)
+	}()
+	span.SetAttribute("mcp.tool", toolName)
+
 	if c.baseURL == "" && c.executor != nil {
-		return c.dispatchLocal(ctx, toolName, args)
+		data, err = c.dispatchLocal(ctx, tool, args)
+	} else {
+		err = c.breaker.Do(func() error {
+			var err error
+			data, err = c.dispatchRemote(ctx, tool, args)
+			return err
+		})
+	}
+	if scope, ok := statsScopes[toolName]; ok && err == nil {
+		data, err = groupStatsData(scope, args, data)
+	}
+	return data, err
+}
+
+func groupStatsData(scope string, args map[string]any, data map[string]any) (map[string]any, error) {
+	rows, err := analytics.StatRows(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s stats: %w", scope, err)
 	}
-	payload := ToolRequest{ToolName: toolName, Arguments: args, SQL: tool.SQLTemplate}
+	term, _ := args["term"].(string)
+	return map[string]any{"scope": scope, "term": term, "groups": analytics.GroupStats(scope, term, rows)}, nil
+}
+
+func GroupStats(data map[string]any) []schema.GroupStats {
+	groups, _ := data["groups"].([]schema.GroupStats)
+	return groups
+}
+
+func (c *Client) dispatchRemote(ctx context.Context, tool Tool, args map[string]any) (map[string]any, error) {
+	payload := ToolRequest{ToolName: tool.Name, Arguments: args, SQL: tool.SQLTemplate}
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("encode tool request: %w", err)
 	}
 
-	endpoint := fmt.Sprintf("%s/v1/tools/%s", c.baseURL, toolName)
+	endpoint := fmt.Sprintf("%s/v1/tools/%s", c.baseURL, tool.Name)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("create tool request: %w", err)
@@ -63,7 +140,7 @@ func (c *Client) Dispatch(ctx context.Context, toolName string, args map[string]
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("tool dispatch failed: status %d", resp.StatusCode)
+		return nil, &breaker.StatusError{Op: "tool dispatch", StatusCode: resp.StatusCode}
 	}
 
 	var decoded ToolResponse
@@ -76,17 +153,100 @@ func (c *Client) Dispatch(ctx context.Context, toolName string, args map[string]
 	return decoded.Data, nil
 }
 
-func (c *Client) dispatchLocal(ctx context.Context, toolName string, args map[string]any) (map[string]any, error) {
-	switch toolName {
+func (c *Client) dispatchLocal(ctx context.Context, tool Tool, args map[string]any) (map[string]any, error) {
+	var run func(ctx context.Context) (map[string]any, error)
+	switch tool.Name {
 	case "query_student_scores":
-		studentID, _ := args["student_id"].(int)
+		studentID, _ := schema.IntArgument(args, "student_id")
+		term, _ := args["term"].(string)
+		if studentID == 0 {
+			return nil, fmt.Errorf("student_id is required")
+		}
+		run = func(ctx context.Context) (map[string]any, error) {
+			return c.executor.QueryStudentScores(ctx, studentID, term)
+		}
+	case "query_student_score_history":
+		studentID, _ := schema.IntArgument(args, "student_id")
+		fromTerm, _ := args["from_term"].(string)
+		toTerm, _ := args["to_term"].(string)
+		if studentID == 0 {
+			return nil, fmt.Errorf("student_id is required")
+		}
+		run = func(ctx context.Context) (map[string]any, error) {
+			return c.executor.QueryStudentScoreHistory(ctx, studentID, fromTerm, toTerm)
+		}
+	case "query_class_stats", "query_grade_stats":
+		studentID, _ := schema.IntArgument(args, "student_id")
 		term, _ := args["term"].(string)
 		if studentID == 0 {
 			return nil, fmt.Errorf("student_id is required")
 		}
-		return c.executor.QueryStudentScores(ctx, studentID, term)
+		query := tool.SQLTemplate
+		run = func(ctx context.Context) (map[string]any, error) {
+			return c.executor.QueryGroupScores(ctx, query, studentID, term)
+		}
+	case "query_student_classes":
+		studentID, _ := schema.IntArgument(args, "student_id")
+		if studentID == 0 {
+			return nil, fmt.Errorf("student_id is required")
+		}
+		run = func(ctx context.Context) (map[string]any, error) {
+			return c.executor.QueryStudentClasses(ctx, studentID)
+		}
+	case "query_class_students":
+		classID, _ := schema.IntArgument(args, "class_id")
+		if classID == 0 {
+			return nil, fmt.Errorf("class_id is required")
+		}
+		run = func(ctx context.Context) (map[string]any, error) {
+			return c.executor.QueryClassStudents(ctx, classID)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported local tool: %s", tool.Name)
+	}
+	var data map[string]any
+	err := c.breaker.Do(func() error {
+		var err error
+		data, err = run(ctx)
+		return err
+	})
+	return data, err
+}
+
+func (c *Client) StudentClassIDs(ctx context.Context, studentID int) ([]int, error) {
+	data, err := c.dispatch(ctx, c.internal["query_student_classes"], map[string]any{"student_id": studentID})
+	if err != nil {
+		return nil, err
+	}
+	return intList(data, "class_ids")
+}
+
+func (c *Client) ClassStudentIDs(ctx context.Context, classID int) ([]int, error) {
+	data, err := c.dispatch(ctx, c.internal["query_class_students"], map[string]any{"class_id": classID})
+	if err != nil {
+		return nil, err
+	}
+	return intList(data, "student_ids")
+}
+
+func intList(data map[string]any, key string) ([]int, error) {
+	switch values := data[key].(type) {
+	case []int:
+		return values, nil
+	case []any:
+		ids := make([]int, 0, len(values))
+		for i := range values {
+			id, ok := schema.IntValue(values[i])
+			if !ok {
+				return nil, fmt.Errorf("%s[%d] is not an integer", key, i)
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	case nil:
+		return []int{}, nil
 	default:
-		return nil, fmt.Errorf("unsupported local tool: %s", toolName)
+		return nil, fmt.Errorf("%s has unexpected type %T", key, values)
 	}
 }
 
@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+
+	"omnibase/internal/tracing"
 )
 
 type SQLExecutor struct {
@@ -30,11 +32,15 @@ func (e *SQLExecutor) Close() error {
 	return e.db.Close()
 }
 
-func (e *SQLExecutor) QueryStudentScores(ctx context.Context, studentID int, term string) (map[string]any, error) {
+func (e *SQLExecutor) QueryStudentScores(ctx context.Context, studentID int, term string) (result map[string]any, err error) {
 	if e == nil || e.db == nil {
 		return nil, errors.New("sql executor not configured")
 	}
 	query := "select subject, score from student_scores where student_id = :student_id and term = :term"
+	ctx, span := tracing.Start(ctx, "sql.query", tracing.SpanKindClient)
+	defer func() { span.Finish(err) }()
+	span.SetAttribute("db.statement", query)
+
 	rows, err := e.db.NamedQueryContext(ctx, query, map[string]any{"student_id": studentID, "term": term})
 	if err != nil {
 		return nil, fmt.Errorf("query scores: %w", err)
@@ -52,3 +58,120 @@ func (e *SQLExecutor) QueryStudentScores(ctx context.Context, studentID int, ter
 	}
 	return map[string]any{"student_id": studentID, "scores": scores}, nil
 }
+
+func (e *SQLExecutor) QueryStudentScoreHistory(ctx context.Context, studentID int, fromTerm, toTerm string) (result map[string]any, err error) {
+	if e == nil || e.db == nil {
+		return nil, errors.New("sql executor not configured")
+	}
+	query := scoreHistorySQL
+	ctx, span := tracing.Start(ctx, "sql.query", tracing.SpanKindClient)
+	defer func() { span.Finish(err) }()
+	span.SetAttribute("db.statement", query)
+
+	rows, err := e.db.NamedQueryContext(ctx, query, map[string]any{"student_id": studentID, "from_term": fromTerm, "to_term": toTerm})
+	if err != nil {
+		return nil, fmt.Errorf("query score history: %w", err)
+	}
+	defer rows.Close()
+
+	scores := make([]map[string]any, 0)
+	for rows.Next() {
+		var term, subject string
+		var score int
+		if err := rows.Scan(&term, &subject, &score); err != nil {
+			return nil, fmt.Errorf("scan score history: %w", err)
+		}
+		scores = append(scores, map[string]any{"term": term, "subject": subject, "score": score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate score history: %w", err)
+	}
+	return map[string]any{"student_id": studentID, "scores": scores}, nil
+}
+
+func (e *SQLExecutor) QueryGroupScores(ctx context.Context, query string, studentID int, term string) (result map[string]any, err error) {
+	if e == nil || e.db == nil {
+		return nil, errors.New("sql executor not configured")
+	}
+	ctx, span := tracing.Start(ctx, "sql.query", tracing.SpanKindClient)
+	defer func() { span.Finish(err) }()
+	span.SetAttribute("db.statement", query)
+
+	rows, err := e.db.NamedQueryContext(ctx, query, map[string]any{"student_id": studentID, "term": term})
+	if err != nil {
+		return nil, fmt.Errorf("query group scores: %w", err)
+	}
+	defer rows.Close()
+
+	groupRows := make([]map[string]any, 0)
+	for rows.Next() {
+		var groupID, subject string
+		var score, isStudent int
+		if err := rows.Scan(&groupID, &subject, &score, &isStudent); err != nil {
+			return nil, fmt.Errorf("scan group scores: %w", err)
+		}
+		groupRows = append(groupRows, map[string]any{"group_id": groupID, "subject": subject, "score": score, "is_student": isStudent})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate group scores: %w", err)
+	}
+	return map[string]any{"student_id": studentID, "term": term, "rows": groupRows}, nil
+}
+
+func (e *SQLExecutor) QueryStudentClasses(ctx context.Context, studentID int) (result map[string]any, err error) {
+	if e == nil || e.db == nil {
+		return nil, errors.New("sql executor not configured")
+	}
+	query := studentClassesSQL
+	ctx, span := tracing.Start(ctx, "sql.query", tracing.SpanKindClient)
+	defer func() { span.Finish(err) }()
+	span.SetAttribute("db.statement", query)
+
+	rows, err := e.db.NamedQueryContext(ctx, query, map[string]any{"student_id": studentID})
+	if err != nil {
+		return nil, fmt.Errorf("query classes: %w", err)
+	}
+	defer rows.Close()
+
+	classIDs := make([]int, 0)
+	for rows.Next() {
+		var classID int
+		if err := rows.Scan(&classID); err != nil {
+			return nil, fmt.Errorf("scan classes: %w", err)
+		}
+		classIDs = append(classIDs, classID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate classes: %w", err)
+	}
+	return map[string]any{"student_id": studentID, "class_ids": classIDs}, nil
+}
+
+func (e *SQLExecutor) QueryClassStudents(ctx context.Context, classID int) (result map[string]any, err error) {
+	if e == nil || e.db == nil {
+		return nil, errors.New("sql executor not configured")
+	}
+	query := classStudentsSQL
+	ctx, span := tracing.Start(ctx, "sql.query", tracing.SpanKindClient)
+	defer func() { span.Finish(err) }()
+	span.SetAttribute("db.statement", query)
+
+	rows, err := e.db.NamedQueryContext(ctx, query, map[string]any{"class_id": classID})
+	if err != nil {
+		return nil, fmt.Errorf("query class students: %w", err)
+	}
+	defer rows.Close()
+
+	studentIDs := make([]int, 0)
+	for rows.Next() {
+		var studentID int
+		if err := rows.Scan(&studentID); err != nil {
+			return nil, fmt.Errorf("scan class students: %w", err)
+		}
+		studentIDs = append(studentIDs, studentID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate class students: %w", err)
+	}
+	return map[string]any{"class_id": classID, "student_ids": studentIDs}, nil
+}
@@ -3,6 +3,8 @@ package mcp
 import (
 	"errors"
 	"strings"
+
+	"omnibase/internal/schema"
 )
 
 type Tool struct {
@@ -36,6 +38,21 @@ func isReadOnlySQL(sql string) bool {
 	return strings.HasPrefix(trimmed, "select ") && !strings.Contains(trimmed, ";")
 }
 
+const scoreHistorySQL = "select term, subject, score from student_scores where student_id = :student_id and (:from_term = '' or term >= :from_term) and (:to_term = '' or term <= :to_term) order by term, subject"
+
+const classStatsSQL = "select cs.class_id as group_id, s.subject, s.score, case when s.student_id = :student_id then 1 else 0 end as is_student from student_scores s join class_students cs on cs.student_id = s.student_id where s.term = :term and cs.class_id in (select own.class_id from class_students own where own.student_id = :student_id)"
+
+const gradeStatsSQL = "select st.grade as group_id, s.subject, s.score, case when s.student_id = :student_id then 1 else 0 end as is_student from student_scores s join students st on st.student_id = s.student_id where s.term = :term and st.grade = (select own.grade from students own where own.student_id = :student_id)"
+
+const studentClassesSQL = "select class_id from class_students where student_id = :student_id"
+
+const classStudentsSQL = "select student_id from class_students where class_id = :class_id order by student_id"
+
+var statsScopes = map[string]string{
+	"query_class_stats": schema.ScopeClass,
+	"query_grade_stats": schema.ScopeGrade,
+}
+
 func DefaultTools() []Tool {
 	return []Tool{
 		{
@@ -51,5 +68,74 @@ func DefaultTools() []Tool {
 			},
 			SQLTemplate: "select subject, score from student_scores where student_id = :student_id and term = :term",
 		},
+		{
+			Name:        "query_student_score_history",
+			Description: "Fetch student scores across all terms or an inclusive term range",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"student_id": map[string]any{"type": "integer"},
+					"from_term":  map[string]any{"type": "string"},
+					"to_term":    map[string]any{"type": "string"},
+				},
+				"required": []string{"student_id"},
+			},
+			SQLTemplate: scoreHistorySQL,
+		},
+		{
+			Name:        "query_class_stats",
+			Description: "Per-subject mean, median, standard deviation and the student's percentile for each class the student is in",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"student_id": map[string]any{"type": "integer"},
+					"term":       map[string]any{"type": "string"},
+				},
+				"required": []string{"student_id", "term"},
+			},
+			SQLTemplate: classStatsSQL,
+		},
+		{
+			Name:        "query_grade_stats",
+			Description: "Per-subject mean, median, standard deviation and the student's percentile across the student's grade",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"student_id": map[string]any{"type": "integer"},
+					"term":       map[string]any{"type": "string"},
+				},
+				"required": []string{"student_id", "term"},
+			},
+			SQLTemplate: gradeStatsSQL,
+		},
+	}
+}
+
+func InternalTools() []Tool {
+	return []Tool{
+		{
+			Name:        "query_student_classes",
+			Description: "List the class IDs a student is enrolled in",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"student_id": map[string]any{"type": "integer"},
+				},
+				"required": []string{"student_id"},
+			},
+			SQLTemplate: studentClassesSQL,
+		},
+		{
+			Name:        "query_class_students",
+			Description: "List the student IDs enrolled in a class",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"class_id": map[string]any{"type": "integer"},
+				},
+				"required": []string{"class_id"},
+			},
+			SQLTemplate: classStudentsSQL,
+		},
 	}
 }
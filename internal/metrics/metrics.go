@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"omnibase/internal/admission"
+	"omnibase/internal/breaker"
+)
+
+type Metrics struct {
+	Registry *Registry
+
+	httpRequests         *CounterVec
+	httpDuration         *HistogramVec
+	nodeDuration         *HistogramVec
+	llmTokens            *CounterVec
+	llmDuration          *HistogramVec
+	embeddingDuration    *HistogramVec
+	qdrantSearchDuration *HistogramVec
+	qdrantResults        *HistogramVec
+	mcpDispatches        *CounterVec
+	mcpOptionalFailures  *CounterVec
+	formatterFailures    *CounterVec
+	ragBelowThreshold    *CounterVec
+	ragNoContext         *CounterVec
+	rerankFallbacks      *CounterVec
+	rewriteFallbacks     *CounterVec
+}
+
+func New() *Metrics {
+	r := NewRegistry()
+	return &Metrics{
+		Registry:             r,
+		httpRequests:         r.NewCounterVec("omnibase_http_requests_total", "HTTP requests by mode, client and status code.", "mode", "client", "status"),
+		httpDuration:         r.NewHistogramVec("omnibase_http_request_duration_seconds", "HTTP request latency by mode and status code.", DefaultLatencyBuckets, "mode", "status"),
+		nodeDuration:         r.NewHistogramVec("omnibase_flow_node_duration_seconds", "Flow node execution time by node and outcome.", DefaultLatencyBuckets, "node", "outcome"),
+		llmTokens:            r.NewCounterVec("omnibase_llm_tokens_total", "LLM tokens reported by the completion API.", "type"),
+		llmDuration:          r.NewHistogramVec("omnibase_llm_request_duration_seconds", "LLM chat completion latency by outcome.", DefaultLatencyBuckets, "outcome"),
+		embeddingDuration:    r.NewHistogramVec("omnibase_embedding_request_duration_seconds", "Embedding request latency by outcome.", DefaultLatencyBuckets, "outcome"),
+		qdrantSearchDuration: r.NewHistogramVec("omnibase_qdrant_search_duration_seconds", "Qdrant search latency by outcome.", DefaultLatencyBuckets, "outcome"),
+		qdrantResults:        r.NewHistogramVec("omnibase_qdrant_search_results", "Number of passages returned per Qdrant search.", []float64{0, 1, 2, 3, 5, 10, 20, 50}),
+		mcpDispatches:        r.NewCounterVec("omnibase_mcp_dispatch_total", "MCP tool dispatches by tool and outcome.", "tool", "outcome"),
+		mcpOptionalFailures:  r.NewCounterVec("omnibase_mcp_optional_tool_failures_total", "Optional enrichment tool calls that failed and were skipped, by tool.", "tool"),
+		formatterFailures:    r.NewCounterVec("omnibase_formatter_validation_failures_total", "LLM responses rejected by the response formatter.", "reason"),
+		ragBelowThreshold:    r.NewCounterVec("omnibase_rag_passages_below_threshold_total", "Retrieved passages dropped for scoring below the minimum similarity."),
+		ragNoContext:         r.NewCounterVec("omnibase_rag_no_context_total", "Requests where no passage passed the similarity threshold, by mode and action.", "mode", "action"),
+		rerankFallbacks:      r.NewCounterVec("omnibase_rerank_fallbacks_total", "Rerank failures that fell back to retrieval order, by backend.", "backend"),
+		rewriteFallbacks:     r.NewCounterVec("omnibase_query_rewrite_fallbacks_total", "Query rewrite failures that fell back to the raw message."),
+	}
+}
+
+func (m *Metrics) ObserveRequest(mode, client string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	code := strconv.Itoa(status)
+	m.httpRequests.Inc(mode, client, code)
+	m.httpDuration.Observe(duration.Seconds(), mode, code)
+}
+
+func (m *Metrics) ObserveNode(node string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.nodeDuration.Observe(duration.Seconds(), node, outcome(err))
+}
+
+func (m *Metrics) ObserveLLMCompletion(duration time.Duration, promptTokens, completionTokens int, err error) {
+	if m == nil {
+		return
+	}
+	m.llmDuration.Observe(duration.Seconds(), outcome(err))
+	m.llmTokens.Add(float64(promptTokens), "prompt")
+	m.llmTokens.Add(float64(completionTokens), "completion")
+}
+
+func (m *Metrics) ObserveEmbedding(duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.embeddingDuration.Observe(duration.Seconds(), outcome(err))
+}
+
+func (m *Metrics) ObserveQdrantSearch(duration time.Duration, results int, err error) {
+	if m == nil {
+		return
+	}
+	m.qdrantSearchDuration.Observe(duration.Seconds(), outcome(err))
+	if err == nil {
+		m.qdrantResults.Observe(float64(results))
+	}
+}
+
+func (m *Metrics) ObserveMCPDispatch(tool string, err error) {
+	if m == nil {
+		return
+	}
+	m.mcpDispatches.Inc(tool, outcome(err))
+}
+
+func (m *Metrics) IncMCPOptionalFailure(tool string) {
+	if m == nil {
+		return
+	}
+	m.mcpOptionalFailures.Inc(tool)
+}
+
+func (m *Metrics) AddRAGBelowThreshold(dropped int) {
+	if m == nil {
+		return
+	}
+	m.ragBelowThreshold.Add(float64(dropped))
+}
+
+func (m *Metrics) IncRAGNoContext(mode, action string) {
+	if m == nil {
+		return
+	}
+	m.ragNoContext.Inc(mode, action)
+}
+
+func (m *Metrics) IncRerankFallback(backend string) {
+	if m == nil {
+		return
+	}
+	m.rerankFallbacks.Inc(backend)
+}
+
+func (m *Metrics) IncQueryRewriteFallback() {
+	if m == nil {
+		return
+	}
+	m.rewriteFallbacks.Inc()
+}
+
+func (m *Metrics) IncFormatterValidationFailure(reason string) {
+	if m == nil {
+		return
+	}
+	m.formatterFailures.Inc(reason)
+}
+
+func (m *Metrics) RegisterBreakers(breakers ...*breaker.Breaker) {
+	m.Registry.NewGaugeFunc("omnibase_circuit_breaker_state", "Circuit breaker state (0 closed, 1 open, 2 half-open).", []string{"dependency"}, func() []Sample {
+		samples := make([]Sample, 0, len(breakers))
+		for _, b := range breakers {
+			samples = append(samples, Sample{Labels: []string{b.Name()}, Value: float64(b.State())})
+		}
+		return samples
+	})
+}
+
+func (m *Metrics) RegisterLimiters(limiters ...*admission.Limiter) {
+	collect := func(value func(admission.Stats) float64) func() []Sample {
+		return func() []Sample {
+			samples := make([]Sample, 0, len(limiters))
+			for _, l := range limiters {
+				samples = append(samples, Sample{Labels: []string{l.Name()}, Value: value(l.Stats())})
+			}
+			return samples
+		}
+	}
+	labels := []string{"limiter"}
+	m.Registry.NewGaugeFunc("omnibase_admission_in_flight", "Weighted slots currently in use.", labels, collect(func(s admission.Stats) float64 { return float64(s.InFlight) }))
+	m.Registry.NewGaugeFunc("omnibase_admission_capacity", "Configured weighted slot capacity.", labels, collect(func(s admission.Stats) float64 { return float64(s.Capacity) }))
+	m.Registry.NewGaugeFunc("omnibase_admission_queued", "Callers waiting for a slot.", labels, collect(func(s admission.Stats) float64 { return float64(s.Queued) }))
+	m.Registry.NewCounterFunc("omnibase_admission_admitted_total", "Callers admitted.", labels, collect(func(s admission.Stats) float64 { return float64(s.Admitted) }))
+	m.Registry.NewCounterFunc("omnibase_admission_rejected_total", "Callers rejected because the queue was full.", labels, collect(func(s admission.Stats) float64 { return float64(s.Rejected) }))
+	m.Registry.NewCounterFunc("omnibase_admission_timed_out_total", "Callers that timed out waiting in the queue.", labels, collect(func(s admission.Stats) float64 { return float64(s.TimedOut) }))
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
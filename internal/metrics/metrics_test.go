@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"omnibase/internal/admission"
+)
+
+func TestMetricsExposition(t *testing.T) {
+	m := New()
+	m.ObserveRequest("customer_support", "key:k1", 200, 120*time.Millisecond)
+	m.ObserveRequest("customer_support", "key:k1", 200, 80*time.Millisecond)
+	m.ObserveNode("rag_retrieval", 10*time.Millisecond, errors.New("qdrant down"))
+	m.ObserveLLMCompletion(time.Second, 120, 30, nil)
+	m.ObserveQdrantSearch(20*time.Millisecond, 4, nil)
+	m.ObserveQdrantSearch(20*time.Millisecond, 0, errors.New("timeout"))
+	m.ObserveMCPDispatch("query_student_scores", nil)
+	m.AddRAGBelowThreshold(3)
+	limiter := admission.New("llm", admission.Config{MaxConcurrent: 4})
+	release, err := limiter.Acquire(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	m.RegisterLimiters(limiter)
+
+	var out strings.Builder
+	if _, err := m.Registry.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"request counter", `omnibase_http_requests_total{mode="customer_support",client="key:k1",status="200"} 2`},
+		{"request histogram", `omnibase_http_request_duration_seconds_bucket{mode="customer_support",status="200",le="0.1"} 1`},
+		{"request sum", `omnibase_http_request_duration_seconds_sum{mode="customer_support",status="200"} 0.2`},
+		{"node outcome", `omnibase_flow_node_duration_seconds_count{node="rag_retrieval",outcome="error"} 1`},
+		{"prompt tokens", `omnibase_llm_tokens_total{type="prompt"} 120`},
+		{"completion tokens", `omnibase_llm_tokens_total{type="completion"} 30`},
+		{"qdrant errors", `omnibase_qdrant_search_duration_seconds_count{outcome="error"} 1`},
+		{"results only on success", `omnibase_qdrant_search_results_count 1`},
+		{"mcp dispatch", `omnibase_mcp_dispatch_total{tool="query_student_scores",outcome="success"} 1`},
+		{"below threshold", `omnibase_rag_passages_below_threshold_total 3`},
+		{"limiter gauge", `omnibase_admission_in_flight{limiter="llm"} 1`},
+		{"limiter counter type", "# TYPE omnibase_admission_admitted_total counter"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(out.String(), tt.want+"\n") {
+			t.Errorf("%s: exposition missing %q", tt.name, tt.want)
+		}
+	}
+}
+
+func TestNilMetricsAreNoOps(t *testing.T) {
+	var m *Metrics
+	m.ObserveRequest("customer_support", "ip", 200, time.Second)
+	m.ObserveNode("rag", time.Second, nil)
+	m.ObserveLLMCompletion(time.Second, 1, 1, nil)
+	m.ObserveEmbedding(time.Second, nil)
+	m.ObserveQdrantSearch(time.Second, 1, nil)
+	m.ObserveMCPDispatch("tool", nil)
+	m.IncMCPOptionalFailure("tool")
+	m.AddRAGBelowThreshold(1)
+	m.IncRAGNoContext("customer_support", "instruct")
+	m.IncRerankFallback("llm")
+	m.IncQueryRewriteFallback()
+	m.IncFormatterValidationFailure("schema")
+}
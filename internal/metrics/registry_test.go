@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"omnibase/internal/breaker"
+)
+
+func TestRegistryExposition(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounterVec("test_requests_total", "Requests.", "mode")
+	counter.Inc("a")
+	counter.Add(2, `b"\`)
+	histogram := registry.NewHistogramVec("test_latency_seconds", "Latency.", []float64{1, 0.1}, "mode")
+	histogram.Observe(0.5, "a")
+	registry.NewGaugeFunc("test_state", "State.", []string{"dependency"}, func() []Sample {
+		return []Sample{{Labels: []string{"llm"}, Value: 1}}
+	})
+
+	var out strings.Builder
+	if _, err := registry.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	for _, want := range []string{
+		"# TYPE test_requests_total counter\n",
+		`test_requests_total{mode="a"} 1` + "\n",
+		`test_requests_total{mode="b\"\\"} 2` + "\n",
+		"# TYPE test_latency_seconds histogram\n",
+		`test_latency_seconds_bucket{mode="a",le="0.1"} 0` + "\n",
+		`test_latency_seconds_bucket{mode="a",le="1"} 1` + "\n",
+		`test_latency_seconds_bucket{mode="a",le="+Inf"} 1` + "\n",
+		`test_latency_seconds_count{mode="a"} 1` + "\n",
+		"# TYPE test_state gauge\n",
+		`test_state{dependency="llm"} 1` + "\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("exposition missing %q\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRegistryRejectsDuplicateNames(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewGaugeFunc("test_state", "State.", nil, func() []Sample { return nil })
+	defer func() {
+		if recover() == nil {
+			t.Fatal("second registration did not panic")
+		}
+	}()
+	registry.NewGaugeFunc("test_state", "State.", nil, func() []Sample { return nil })
+}
+
+func TestRegisterBreakersExposesEachBreakerOnce(t *testing.T) {
+	m := New()
+	config := breaker.Config{FailureThreshold: 1, OpenTimeout: time.Minute}
+	llm, rerank := breaker.New("llm", config), breaker.New("rerank", config)
+	_ = rerank.Do(func() error { return &breaker.StatusError{Op: "rerank", StatusCode: 503} })
+	m.RegisterBreakers(llm, rerank)
+
+	var out strings.Builder
+	if _, err := m.Registry.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if got := strings.Count(out.String(), "# TYPE omnibase_circuit_breaker_state "); got != 1 {
+		t.Fatalf("breaker state TYPE lines = %d, want 1", got)
+	}
+	for _, want := range []string{`omnibase_circuit_breaker_state{dependency="llm"} 0`, `omnibase_circuit_breaker_state{dependency="rerank"} 1`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("exposition missing %q", want)
+		}
+	}
+}
@@ -3,44 +3,232 @@ package qdrant
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
 
 	qdrantclient "github.com/qdrant/go-client/qdrant"
+
+	"omnibase/internal/breaker"
+	"omnibase/internal/metrics"
+	"omnibase/internal/schema"
+	"omnibase/internal/tracing"
 )
 
 type Client struct {
 	client     *qdrantclient.Client
 	collection string
+	breaker    *breaker.Breaker
+	metrics    *metrics.Metrics
+	dense      string
+	sparse     string
+	optional   map[string]bool
 }
 
 func NewClient(baseURL, apiKey, collection string) *Client {
 	return &Client{
-		client:     qdrantclient.NewClient(baseURL, apiKey),
+		client:     qdrantclient.NewClient(baseURL, apiKey).WithHTTPClient(&http.Client{Transport: tracing.NewTransport(nil)}),
 		collection: collection,
 	}
 }
 
-func (c *Client) Search(ctx context.Context, vector []float32, limit int) ([]string, error) {
-	if c.client == nil {
-		return nil, fmt.Errorf("qdrant client not configured")
+func (c *Client) WithBreaker(b *breaker.Breaker) *Client {
+	c.breaker = b
+	return c
+}
+
+func (c *Client) WithMetrics(m *metrics.Metrics) *Client {
+	c.metrics = m
+	return c
+}
+
+func (c *Client) WithVectorNames(dense, sparse string) *Client {
+	c.dense = dense
+	c.sparse = sparse
+	return c
+}
+
+func (c *Client) WithOptionalFilterFields(fields ...string) *Client {
+	c.optional = make(map[string]bool, len(fields))
+	for _, field := range fields {
+		c.optional[field] = true
 	}
+	return c
+}
+
+func (c *Client) SparseEnabled() bool {
+	return c != nil && c.sparse != ""
+}
+
+func (c *Client) VectorSize(ctx context.Context) (int, error) {
+	info, err := c.client.GetCollection(ctx, c.collection)
+	if err != nil {
+		return 0, err
+	}
+	params, ok := info.Config.Params.Vectors.Get(c.dense)
+	if !ok {
+		return 0, fmt.Errorf("collection %s has no dense vector %q", c.collection, c.dense)
+	}
+	return int(params.Size), nil
+}
+
+const ModifierIDF = "idf"
+
+func (c *Client) SparseModifier(ctx context.Context) (string, error) {
+	info, err := c.client.GetCollection(ctx, c.collection)
+	if err != nil {
+		return "", err
+	}
+	params, ok := info.Config.Params.SparseVectors[c.sparse]
+	if !ok {
+		return "", fmt.Errorf("collection %s has no sparse vector %q", c.collection, c.sparse)
+	}
+	return params.Modifier, nil
+}
+
+type SparseVector = qdrantclient.SparseVector
+
+type SearchRequest struct {
+	Vector     []float32
+	Sparse     *SparseVector
+	Limit      int
+	Filters    map[string]string
+	IDs        []string
+	WithVector bool
+}
+
+func (c *Client) Search(ctx context.Context, req SearchRequest) (passages []schema.Passage, err error) {
+	ctx, span := tracing.Start(ctx, "qdrant.search", tracing.SpanKindClient)
+	defer func() { span.Finish(err) }()
+	limit := req.Limit
 	if limit <= 0 {
 		limit = 5
 	}
-	resp, err := c.client.SearchPoints(ctx, qdrantclient.SearchPointsRequest{
-		Collection:  c.collection,
-		Vector:      vector,
-		Limit:       uint32(limit),
-		WithPayload: true,
+	span.SetAttribute("qdrant.collection", c.collection)
+	span.SetAttribute("qdrant.limit", limit)
+	span.SetAttribute("qdrant.filter_keys", len(req.Filters))
+	span.SetAttribute("qdrant.sparse", req.Sparse != nil)
+
+	if c.client == nil {
+		return nil, fmt.Errorf("qdrant client not configured")
+	}
+	vectorName := c.dense
+	if req.Sparse != nil {
+		if c.sparse == "" {
+			return nil, fmt.Errorf("qdrant sparse vector name not configured")
+		}
+		vectorName = c.sparse
+	}
+	start := time.Now()
+	defer func() { c.metrics.ObserveQdrantSearch(time.Since(start), len(passages), err) }()
+	var resp qdrantclient.SearchPointsResponse
+	err = c.breaker.Do(func() error {
+		var err error
+		resp, err = c.client.SearchPoints(ctx, qdrantclient.SearchPointsRequest{
+			Collection:   c.collection,
+			Vector:       req.Vector,
+			VectorName:   vectorName,
+			SparseVector: req.Sparse,
+			Limit:        uint32(limit),
+			WithPayload:  true,
+			WithVector:   req.WithVector && c.dense == "",
+			WithVectors:  c.withVectors(req.WithVector),
+			Filter:       searchFilter(req.Filters, c.optional, req.IDs),
+		})
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	passages := make([]string, 0, len(resp.Result))
-	for _, item := range resp.Result {
-		if text, ok := item.Payload["text"].(string); ok {
-			passages = append(passages, text)
+	passages = make([]schema.Passage, 0, len(resp.Result))
+	for _, point := range resp.Result {
+		if passage, ok := toPassage(point, c.dense); ok {
+			passages = append(passages, passage)
 		}
 	}
+	span.SetAttribute("qdrant.result_count", len(passages))
 	return passages, nil
 }
+
+func (c *Client) withVectors(enabled bool) []string {
+	if !enabled || c.dense == "" {
+		return nil
+	}
+	return []string{c.dense}
+}
+
+func searchFilter(fields map[string]string, optional map[string]bool, ids []string) *qdrantclient.Filter {
+	filter := payloadFilter(fields, optional)
+	if len(ids) == 0 {
+		return filter
+	}
+	if filter == nil {
+		filter = &qdrantclient.Filter{}
+	}
+	pointIDs := make([]qdrantclient.PointID, 0, len(ids))
+	for _, id := range ids {
+		if num, err := strconv.ParseUint(id, 10, 64); err == nil {
+			pointIDs = append(pointIDs, qdrantclient.PointID{Num: num})
+		} else {
+			pointIDs = append(pointIDs, qdrantclient.PointID{UUID: id})
+		}
+	}
+	filter.Must = append(filter.Must, qdrantclient.NewHasID(pointIDs...))
+	return filter
+}
+
+func payloadFilter(fields map[string]string, optional map[string]bool) *qdrantclient.Filter {
+	if len(fields) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	filter := &qdrantclient.Filter{}
+	for _, key := range keys {
+		if !optional[key] {
+			filter.Must = append(filter.Must, qdrantclient.NewMatch(key, fields[key]))
+			continue
+		}
+		filter.Must = append(filter.Must, qdrantclient.NewFilterCondition(qdrantclient.Filter{
+			Should: []qdrantclient.Condition{qdrantclient.NewMatch(key, fields[key]), qdrantclient.NewIsEmpty(key)},
+		}))
+	}
+	return filter
+}
+
+func toPassage(point qdrantclient.ScoredPoint, vectorName string) (schema.Passage, bool) {
+	text, ok := point.Payload["text"].(string)
+	if !ok {
+		return schema.Passage{}, false
+	}
+	score, _ := strconv.ParseFloat(strconv.FormatFloat(float64(point.Score), 'g', -1, 32), 64)
+	passage := schema.Passage{ID: point.ID.String(), Score: score, Text: text, Vector: point.Vector.Get(vectorName)}
+	for key, value := range point.Payload {
+		switch key {
+		case "text":
+		case "title":
+			passage.Title, _ = value.(string)
+		case "source":
+			passage.Source, _ = value.(string)
+		case "section":
+			passage.Section, _ = value.(string)
+		case "url":
+			passage.URL, _ = value.(string)
+		case "chunk_index":
+			if index, ok := schema.IntValue(value); ok {
+				passage.ChunkIndex = &index
+			}
+		default:
+			if passage.Metadata == nil {
+				passage.Metadata = make(map[string]any)
+			}
+			passage.Metadata[key] = value
+		}
+	}
+	return passage, true
+}
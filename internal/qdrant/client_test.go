@@ -0,0 +1,46 @@
+package qdrant
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSearchFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   map[string]string
+		optional map[string]bool
+		ids      []string
+		want     string
+	}{
+		{"none", nil, nil, nil, `null`},
+		{
+			"strict by default",
+			map[string]string{"version": "v2", "product": "omni"},
+			nil, nil,
+			`{"must":[{"key":"product","match":{"value":"omni"}},{"key":"version","match":{"value":"v2"}}]}`,
+		},
+		{
+			"optional field matches missing values",
+			map[string]string{"version": "v2", "language": "en"},
+			map[string]bool{"language": true}, nil,
+			`{"must":[{"should":[{"key":"language","match":{"value":"en"}},{"is_empty":{"key":"language"}}]},{"key":"version","match":{"value":"v2"}}]}`,
+		},
+		{
+			"ids only",
+			nil, nil, []string{"7", "2b2e5f1a-0c4f-4c47-9a0b-3c1c1e0b8f00"},
+			`{"must":[{"has_id":[7,"2b2e5f1a-0c4f-4c47-9a0b-3c1c1e0b8f00"]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(searchFilter(tt.fields, tt.optional, tt.ids))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("searchFilter() = %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+func (r Rule) Enabled() bool {
+	return r.Rate > 0 && r.Burst > 0
+}
+
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type Limiter struct {
+	idleTTL time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewLimiter(idleTTL time.Duration) *Limiter {
+	if idleTTL <= 0 {
+		idleTTL = 10 * time.Minute
+	}
+	return &Limiter{idleTTL: idleTTL, now: time.Now, buckets: make(map[string]*bucket)}
+}
+
+func (l *Limiter) Allow(key string, rule Rule) Decision {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Burst), last: now}
+		l.buckets[key] = b
+	}
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(rule.Burst), b.tokens+elapsed*rule.Rate)
+	b.last = now
+
+	decision := Decision{Limit: rule.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = secondsDuration((1 - b.tokens) / rule.Rate)
+	}
+	decision.Remaining = int(math.Floor(b.tokens))
+	decision.Reset = secondsDuration((float64(rule.Burst) - b.tokens) / rule.Rate)
+	return decision
+}
+
+func (l *Limiter) Evict() int {
+	cutoff := l.now().Add(-l.idleTTL)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	evicted := 0
+	for key, b := range l.buckets {
+		if b.last.Before(cutoff) {
+			delete(l.buckets, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+func (l *Limiter) RunEviction(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.Evict()
+		}
+	}
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+type Policy struct {
+	Limiter *Limiter
+	Default Rule
+	Routes  map[string]Rule
+	Modes   map[string]Rule
+}
+
+func (p *Policy) AllowRoute(client, path string) (Decision, bool) {
+	if p == nil {
+		return Decision{}, false
+	}
+	rule, scope, ok := p.routeRule(path)
+	if !ok {
+		return Decision{}, false
+	}
+	return p.Limiter.Allow("route:"+scope+":"+client, rule), true
+}
+
+func (p *Policy) AllowMode(client, mode string) (Decision, bool) {
+	if p == nil {
+		return Decision{}, false
+	}
+	rule, ok := p.Modes[mode]
+	if !ok || !rule.Enabled() {
+		return Decision{}, false
+	}
+	return p.Limiter.Allow("mode:"+mode+":"+client, rule), true
+}
+
+func (p *Policy) routeRule(path string) (Rule, string, bool) {
+	best := ""
+	var matched Rule
+	for prefix, rule := range p.Routes {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(best) {
+			best, matched = prefix, rule
+		}
+	}
+	if best != "" && matched.Enabled() {
+		return matched, best, true
+	}
+	if p.Default.Enabled() {
+		return p.Default, "default", true
+	}
+	return Rule{}, "", false
+}
+
+func ParsePolicy(spec string) (Policy, error) {
+	policy := Policy{Routes: make(map[string]Rule), Modes: make(map[string]Rule)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		scope, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return Policy{}, fmt.Errorf("rate limit %q must be scope=rate/period:burst", entry)
+		}
+		rule, err := parseRule(value)
+		if err != nil {
+			return Policy{}, fmt.Errorf("rate limit %q: %w", entry, err)
+		}
+		switch kind, name, _ := strings.Cut(scope, ":"); kind {
+		case "default":
+			policy.Default = rule
+		case "route":
+			policy.Routes[name] = rule
+		case "mode":
+			policy.Modes[name] = rule
+		default:
+			return Policy{}, fmt.Errorf("rate limit %q: scope must be default, route:<path> or mode:<mode>", entry)
+		}
+	}
+	return policy, nil
+}
+
+func parseRule(value string) (Rule, error) {
+	rateSpec, burstSpec, ok := strings.Cut(value, ":")
+	if !ok {
+		return Rule{}, fmt.Errorf("missing burst")
+	}
+	countSpec, periodSpec, ok := strings.Cut(rateSpec, "/")
+	if !ok {
+		return Rule{}, fmt.Errorf("missing period")
+	}
+	count, err := strconv.ParseFloat(countSpec, 64)
+	if err != nil || count <= 0 {
+		return Rule{}, fmt.Errorf("count must be a positive number")
+	}
+	if periodSpec == "" {
+		return Rule{}, fmt.Errorf("period must be a positive duration")
+	}
+	if !strings.ContainsAny(periodSpec[:1], "0123456789") {
+		periodSpec = "1" + periodSpec
+	}
+	period, err := time.ParseDuration(periodSpec)
+	if err != nil || period <= 0 {
+		return Rule{}, fmt.Errorf("period must be a positive duration")
+	}
+	burst, err := strconv.Atoi(burstSpec)
+	if err != nil || burst <= 0 {
+		return Rule{}, fmt.Errorf("burst must be a positive integer")
+	}
+	return Rule{Rate: count / period.Seconds(), Burst: burst}, nil
+}
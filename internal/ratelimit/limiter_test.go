@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestLimiter() (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	l := NewLimiter(time.Minute)
+	l.now = clock.Now
+	return l, clock
+}
+
+func TestLimiterAllow(t *testing.T) {
+	rule := Rule{Rate: 1, Burst: 2}
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		allowed   bool
+		remaining int
+		retry     time.Duration
+	}{
+		{"first burst token", 0, true, 1, 0},
+		{"second burst token", 0, true, 0, 0},
+		{"burst exhausted", 0, false, 0, time.Second},
+		{"partial refill", 500 * time.Millisecond, false, 0, 500 * time.Millisecond},
+		{"refilled token", 500 * time.Millisecond, true, 0, 0},
+		{"refill capped at burst", time.Hour, true, 1, 0},
+	}
+	l, clock := newTestLimiter()
+	for _, tt := range tests {
+		clock.now = clock.now.Add(tt.advance)
+		decision := l.Allow("client", rule)
+		if decision.Allowed != tt.allowed || decision.Remaining != tt.remaining || decision.RetryAfter != tt.retry || decision.Limit != 2 {
+			t.Fatalf("%s: decision = %+v", tt.name, decision)
+		}
+	}
+	if decision := l.Allow("other", rule); !decision.Allowed {
+		t.Fatal("buckets are shared between keys")
+	}
+}
+
+func TestLimiterEvict(t *testing.T) {
+	l, clock := newTestLimiter()
+	l.Allow("idle", Rule{Rate: 1, Burst: 1})
+	clock.now = clock.now.Add(2 * time.Minute)
+	l.Allow("active", Rule{Rate: 1, Burst: 1})
+	if evicted := l.Evict(); evicted != 1 {
+		t.Fatalf("Evict() = %d, want 1", evicted)
+	}
+	if decision := l.Allow("active", Rule{Rate: 1, Burst: 1}); decision.Allowed {
+		t.Fatal("active bucket was evicted")
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		check   func(Policy) bool
+		wantErr bool
+	}{
+		{"empty", "", func(p Policy) bool { return !p.Default.Enabled() && len(p.Routes) == 0 }, false},
+		{"default per minute", "default=60/m:10", func(p Policy) bool { return p.Default == Rule{Rate: 1, Burst: 10} }, false},
+		{"route and mode", "route:/v1/batch=2/10s:2, mode:student_analysis=30/1m:5", func(p Policy) bool {
+			return p.Routes["/v1/batch"] == Rule{Rate: 0.2, Burst: 2} && p.Modes["student_analysis"] == Rule{Rate: 0.5, Burst: 5}
+		}, false},
+		{"missing equals", "default", nil, true},
+		{"missing burst", "default=10/s", nil, true},
+		{"missing period", "default=10:5", nil, true},
+		{"zero count", "default=0/s:5", nil, true},
+		{"bad period", "default=10/fortnight:5", nil, true},
+		{"zero burst", "default=10/s:0", nil, true},
+		{"unknown scope", "client:abc=10/s:5", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParsePolicy(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePolicy(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if err == nil && !tt.check(policy) {
+				t.Fatalf("ParsePolicy(%q) = %+v", tt.spec, policy)
+			}
+		})
+	}
+}
+
+func TestPolicyScopes(t *testing.T) {
+	policy, err := ParsePolicy("default=1/h:1,route:/v1=1/h:1,route:/v1/jobs=1/h:1,mode:customer_support=1/h:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := newTestLimiter()
+	policy.Limiter = l
+
+	tests := []struct {
+		name    string
+		allow   func() (Decision, bool)
+		limited bool
+		allowed bool
+	}{
+		{"longest route prefix", func() (Decision, bool) { return policy.AllowRoute("a", "/v1/jobs/123") }, true, true},
+		{"same route scope exhausted", func() (Decision, bool) { return policy.AllowRoute("a", "/v1/jobs") }, true, false},
+		{"shorter prefix has its own bucket", func() (Decision, bool) { return policy.AllowRoute("a", "/v1/support/answer") }, true, true},
+		{"other client", func() (Decision, bool) { return policy.AllowRoute("b", "/v1/jobs") }, true, true},
+		{"default scope", func() (Decision, bool) { return policy.AllowRoute("a", "/healthz") }, true, true},
+		{"mode", func() (Decision, bool) { return policy.AllowMode("a", "customer_support") }, true, true},
+		{"mode exhausted", func() (Decision, bool) { return policy.AllowMode("a", "customer_support") }, true, false},
+		{"mode without rule", func() (Decision, bool) { return policy.AllowMode("a", "student_analysis") }, false, false},
+	}
+	for _, tt := range tests {
+		decision, limited := tt.allow()
+		if limited != tt.limited || decision.Allowed != tt.allowed {
+			t.Fatalf("%s: limited = %v decision = %+v", tt.name, limited, decision)
+		}
+	}
+
+	var none *Policy
+	if _, limited := none.AllowRoute("a", "/v1"); limited {
+		t.Error("nil policy limited a request")
+	}
+}
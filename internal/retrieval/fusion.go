@@ -0,0 +1,93 @@
+package retrieval
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"omnibase/internal/schema"
+)
+
+const (
+	RRFConstant          = 60
+	DefaultKeywordWeight = 0.5
+)
+
+const (
+	ScoreDense   = "dense"
+	ScoreKeyword = "keyword"
+	ScoreRRF     = "rrf"
+	ScoreRerank  = "rerank"
+)
+
+type RankedList struct {
+	Name     string
+	Weight   float64
+	Passages []schema.Passage
+}
+
+func Fuse(lists []RankedList, limit int) []schema.Passage {
+	fused := make(map[string]*schema.Passage)
+	order := make([]string, 0)
+	for _, list := range lists {
+		if list.Weight <= 0 {
+			continue
+		}
+		for rank, passage := range list.Passages {
+			entry, ok := fused[passage.ID]
+			if !ok {
+				copied := passage
+				copied.Score = 0
+				copied.Scores = make(map[string]float64, len(lists)+1)
+				entry = &copied
+				fused[passage.ID] = entry
+				order = append(order, passage.ID)
+			}
+			if score, ok := entry.Scores[list.Name]; !ok || passage.Score > score {
+				entry.Scores[list.Name] = passage.Score
+			}
+			entry.Scores[ScoreRRF] += list.Weight / float64(RRFConstant+rank+1)
+		}
+	}
+	passages := make([]schema.Passage, 0, len(order))
+	for _, id := range order {
+		passage := fused[id]
+		passage.Score = passage.Scores[ScoreDense]
+		passages = append(passages, *passage)
+	}
+	sort.SliceStable(passages, func(i, j int) bool { return passages[i].Scores[ScoreRRF] > passages[j].Scores[ScoreRRF] })
+	if limit > 0 && len(passages) > limit {
+		passages = passages[:limit]
+	}
+	return passages
+}
+
+func RankingScore(passage schema.Passage) float64 {
+	for _, name := range []string{ScoreRerank, ScoreRRF} {
+		if score, ok := passage.Scores[name]; ok {
+			return score
+		}
+	}
+	return passage.Score
+}
+
+func ParseWeights(spec string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		mode, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("keyword weight %q must be mode=weight", entry)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 || weight > 1 {
+			return nil, fmt.Errorf("keyword weight %q must be between 0 and 1", entry)
+		}
+		weights[strings.TrimSpace(mode)] = weight
+	}
+	return weights, nil
+}
@@ -0,0 +1,118 @@
+package retrieval
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"omnibase/internal/schema"
+)
+
+func ids(passages []schema.Passage) string {
+	out := make([]string, 0, len(passages))
+	for _, passage := range passages {
+		out = append(out, passage.ID)
+	}
+	return strings.Join(out, ",")
+}
+
+func list(name string, weight float64, ids ...string) RankedList {
+	passages := make([]schema.Passage, 0, len(ids))
+	for i, id := range ids {
+		passages = append(passages, schema.Passage{ID: id, Score: 1 - float64(i)/10})
+	}
+	return RankedList{Name: name, Weight: weight, Passages: passages}
+}
+
+func TestFuse(t *testing.T) {
+	tests := []struct {
+		name  string
+		lists []RankedList
+		limit int
+		want  string
+	}{
+		{"single list keeps order", []RankedList{list(ScoreDense, 1, "a", "b", "c")}, 0, "a,b,c"},
+		{"shared hits rise", []RankedList{list(ScoreDense, 0.5, "a", "b", "c"), list(ScoreKeyword, 0.5, "c", "b", "d")}, 0, "c,b,a,d"},
+		{"weight favours keyword list", []RankedList{list(ScoreDense, 0.2, "a", "b"), list(ScoreKeyword, 0.8, "b", "c")}, 0, "b,c,a"},
+		{"zero weight list ignored", []RankedList{list(ScoreDense, 1, "a", "b"), list(ScoreKeyword, 0, "c")}, 0, "a,b"},
+		{"limit", []RankedList{list(ScoreDense, 1, "a", "b", "c")}, 2, "a,b"},
+		{"multi-query lists of one kind", []RankedList{list(ScoreDense, 1, "a", "b"), list(ScoreDense, 1, "b", "a")}, 0, "a,b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(Fuse(tt.lists, tt.limit)); got != tt.want {
+				t.Fatalf("Fuse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuseScores(t *testing.T) {
+	fused := Fuse([]RankedList{list(ScoreDense, 0.5, "a", "b"), list(ScoreKeyword, 0.5, "b", "c"), list(ScoreDense, 0.5, "c", "b")}, 0)
+	byID := make(map[string]schema.Passage, len(fused))
+	for _, passage := range fused {
+		byID[passage.ID] = passage
+	}
+	b := byID["b"]
+	if want := 0.5/62 + 0.5/61 + 0.5/62; math.Abs(b.Scores[ScoreRRF]-want) > 1e-12 {
+		t.Errorf("rrf(b) = %v, want %v", b.Scores[ScoreRRF], want)
+	}
+	if b.Scores[ScoreDense] != 0.9 || b.Scores[ScoreKeyword] != 1 || b.Score != 0.9 {
+		t.Errorf("b scores = %v score = %v, want best dense 0.9 and keyword 1", b.Scores, b.Score)
+	}
+	if c := byID["c"]; c.Score != 1 || c.Scores[ScoreKeyword] != 0.9 {
+		t.Errorf("c scores = %v score = %v", c.Scores, c.Score)
+	}
+	if a := byID["a"]; RankingScore(a) != a.Scores[ScoreRRF] {
+		t.Errorf("RankingScore(a) = %v, want rrf %v", RankingScore(a), a.Scores[ScoreRRF])
+	}
+}
+
+func TestRankingScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		passage schema.Passage
+		want    float64
+	}{
+		{"plain score", schema.Passage{Score: 0.8}, 0.8},
+		{"rrf over score", schema.Passage{Score: 0.8, Scores: map[string]float64{ScoreRRF: 0.03}}, 0.03},
+		{"rerank over rrf", schema.Passage{Score: 0.8, Scores: map[string]float64{ScoreRRF: 0.03, ScoreRerank: 0.6}}, 0.6},
+	}
+	for _, tt := range tests {
+		if got := RankingScore(tt.passage); got != tt.want {
+			t.Errorf("%s: RankingScore() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{"", map[string]float64{}, false},
+		{"customer_support=0.3, student_analysis = 0", map[string]float64{"customer_support": 0.3, "student_analysis": 0}, false},
+		{"customer_support", nil, true},
+		{"customer_support=1.5", nil, true},
+		{"customer_support=-0.1", nil, true},
+		{"customer_support=high", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseWeights(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseWeights(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("ParseWeights(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+		for mode, weight := range tt.want {
+			if got[mode] != weight {
+				t.Fatalf("ParseWeights(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		}
+	}
+}
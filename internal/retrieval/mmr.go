@@ -0,0 +1,100 @@
+package retrieval
+
+import (
+	"math"
+	"slices"
+
+	"omnibase/internal/schema"
+)
+
+type Diversity struct {
+	Lambda         float64
+	Candidates     int
+	MaxPerDocument int
+}
+
+func (d Diversity) Enabled() bool {
+	return d.Candidates > 0
+}
+
+func SelectMMR(passages []schema.Passage, limit int, lambda float64, maxPerDocument int) []schema.Passage {
+	relevance := normalizedScores(passages)
+	selected := make([]int, 0, limit)
+	perDocument := make(map[string]int)
+	for len(selected) < limit {
+		best, bestScore := -1, math.Inf(-1)
+		for i := range passages {
+			if slices.Contains(selected, i) {
+				continue
+			}
+			if maxPerDocument > 0 && perDocument[DocumentKey(passages[i])] >= maxPerDocument {
+				continue
+			}
+			var redundancy float64
+			for _, j := range selected {
+				redundancy = max(redundancy, cosine(passages[i].Vector, passages[j].Vector))
+			}
+			score := lambda*relevance[i] - (1-lambda)*redundancy
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			break
+		}
+		selected = append(selected, best)
+		perDocument[DocumentKey(passages[best])]++
+	}
+	result := make([]schema.Passage, 0, len(selected))
+	for _, i := range selected {
+		result = append(result, passages[i])
+	}
+	return result
+}
+
+func DocumentKey(passage schema.Passage) string {
+	switch {
+	case passage.URL != "":
+		return passage.URL
+	case passage.Source != "":
+		return passage.Source
+	case passage.Title != "":
+		return passage.Title
+	default:
+		return "id:" + passage.ID
+	}
+}
+
+func normalizedScores(passages []schema.Passage) []float64 {
+	scores := make([]float64, len(passages))
+	if len(passages) == 0 {
+		return scores
+	}
+	low, high := RankingScore(passages[0]), RankingScore(passages[0])
+	for _, passage := range passages {
+		low, high = min(low, RankingScore(passage)), max(high, RankingScore(passage))
+	}
+	for i, passage := range passages {
+		scores[i] = 1
+		if high > low {
+			scores[i] = (RankingScore(passage) - low) / (high - low)
+		}
+	}
+	return scores
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
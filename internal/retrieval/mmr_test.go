@@ -0,0 +1,66 @@
+package retrieval
+
+import (
+	"testing"
+
+	"omnibase/internal/schema"
+)
+
+func TestSelectMMR(t *testing.T) {
+	near := []float32{1, 0, 0}
+	nearCopy := []float32{0.99, 0.01, 0}
+	far := []float32{0, 1, 0}
+	passages := []schema.Passage{
+		{ID: "a", Score: 0.9, Vector: near, Source: "guide"},
+		{ID: "b", Score: 0.85, Vector: nearCopy, Source: "guide"},
+		{ID: "c", Score: 0.7, Vector: far, Source: "faq"},
+		{ID: "d", Score: 0.6, Vector: near, Source: "faq"},
+	}
+	tests := []struct {
+		name           string
+		limit          int
+		lambda         float64
+		maxPerDocument int
+		want           string
+	}{
+		{"relevance only", 3, 1, 0, "a,b,c"},
+		{"diversity promotes distinct passage", 2, 0.5, 0, "a,c"},
+		{"per-document cap", 3, 1, 1, "a,c"},
+		{"limit above candidates", 10, 1, 0, "a,b,c,d"},
+		{"zero limit", 0, 0.5, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(SelectMMR(passages, tt.limit, tt.lambda, tt.maxPerDocument)); got != tt.want {
+				t.Fatalf("SelectMMR() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectMMRUsesRankingScore(t *testing.T) {
+	passages := []schema.Passage{
+		{ID: "a", Score: 0.9, Scores: map[string]float64{ScoreRerank: 0.1}},
+		{ID: "b", Score: 0.5, Scores: map[string]float64{ScoreRerank: 0.8}},
+	}
+	if got := ids(SelectMMR(passages, 1, 1, 0)); got != "b" {
+		t.Fatalf("SelectMMR() = %s, want b", got)
+	}
+}
+
+func TestDocumentKey(t *testing.T) {
+	tests := []struct {
+		passage schema.Passage
+		want    string
+	}{
+		{schema.Passage{ID: "1", URL: "https://docs/a", Source: "a.md", Title: "A"}, "https://docs/a"},
+		{schema.Passage{ID: "1", Source: "a.md", Title: "A"}, "a.md"},
+		{schema.Passage{ID: "1", Title: "A"}, "A"},
+		{schema.Passage{ID: "1"}, "id:1"},
+	}
+	for _, tt := range tests {
+		if got := DocumentKey(tt.passage); got != tt.want {
+			t.Errorf("DocumentKey(%+v) = %s, want %s", tt.passage, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package retrieval
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	BM25K1 = 1.2
+	BM25B  = 0.75
+)
+
+var stopwords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "also": true, "am": true, "an": true, "and": true,
+	"any": true, "are": true, "as": true, "at": true, "be": true, "been": true, "but": true, "by": true,
+	"can": true, "could": true, "did": true, "do": true, "does": true, "for": true, "from": true, "get": true,
+	"had": true, "has": true, "have": true, "how": true, "i": true, "if": true, "in": true, "into": true,
+	"is": true, "it": true, "its": true, "me": true, "my": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "our": true, "please": true, "should": true, "so": true, "than": true, "that": true,
+	"the": true, "their": true, "them": true, "then": true, "there": true, "these": true, "they": true, "this": true,
+	"to": true, "was": true, "we": true, "were": true, "what": true, "when": true, "where": true, "which": true,
+	"who": true, "why": true, "will": true, "with": true, "would": true, "you": true, "your": true,
+}
+
+type SparseVector struct {
+	Indices []uint32
+	Values  []float32
+}
+
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.Trim(field, "-_")
+		if field == "" || stopwords[field] {
+			continue
+		}
+		tokens = append(tokens, field)
+		if strings.ContainsAny(field, "-_") {
+			for _, part := range strings.FieldsFunc(field, func(r rune) bool { return r == '-' || r == '_' }) {
+				if !stopwords[part] {
+					tokens = append(tokens, part)
+				}
+			}
+		}
+	}
+	return tokens
+}
+
+func EncodeQuery(text string) SparseVector {
+	weights := make(map[uint32]float32)
+	for _, token := range Tokenize(text) {
+		weights[tokenIndex(token)] = 1
+	}
+	return sparseVector(weights)
+}
+
+func EncodeDocument(text string, averageLength float64) SparseVector {
+	tokens := Tokenize(text)
+	counts := make(map[uint32]float64)
+	for _, token := range tokens {
+		counts[tokenIndex(token)]++
+	}
+	if averageLength <= 0 {
+		averageLength = float64(len(tokens))
+	}
+	norm := 1 - BM25B + BM25B*float64(len(tokens))/averageLength
+	weights := make(map[uint32]float32, len(counts))
+	for index, tf := range counts {
+		weights[index] = float32(tf * (BM25K1 + 1) / (tf + BM25K1*norm))
+	}
+	return sparseVector(weights)
+}
+
+func tokenIndex(token string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(token))
+	return h.Sum32()
+}
+
+func sparseVector(weights map[uint32]float32) SparseVector {
+	vector := SparseVector{Indices: make([]uint32, 0, len(weights)), Values: make([]float32, 0, len(weights))}
+	for index := range weights {
+		vector.Indices = append(vector.Indices, index)
+	}
+	sort.Slice(vector.Indices, func(i, j int) bool { return vector.Indices[i] < vector.Indices[j] })
+	for _, index := range vector.Indices {
+		vector.Values = append(vector.Values, weights[index])
+	}
+	return vector
+}
@@ -0,0 +1,82 @@
+package retrieval
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"How do I reset my password?", []string{"reset", "password"}},
+		{"Error ERR-1042 at checkout", []string{"error", "err-1042", "err", "1042", "checkout"}},
+		{"snake_case_name and -dashes-", []string{"snake_case_name", "snake", "case", "name", "dashes"}},
+		{"Größe über 10", []string{"größe", "über", "10"}},
+		{"the and of", []string{}},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeQuery(t *testing.T) {
+	vector := EncodeQuery("password reset PASSWORD")
+	if len(vector.Indices) != 2 || len(vector.Values) != 2 {
+		t.Fatalf("EncodeQuery() = %+v, want two unique terms", vector)
+	}
+	if !slices.IsSorted(vector.Indices) {
+		t.Errorf("indices not sorted: %v", vector.Indices)
+	}
+	for _, value := range vector.Values {
+		if value != 1 {
+			t.Errorf("query weight = %v, want 1", value)
+		}
+	}
+	if want := []uint32{tokenIndex("password"), tokenIndex("reset")}; !slices.Equal(vector.Indices, sortedCopy(want)) {
+		t.Errorf("indices = %v, want %v", vector.Indices, sortedCopy(want))
+	}
+}
+
+func TestEncodeDocument(t *testing.T) {
+	weight := func(vector SparseVector, token string) float32 {
+		i := slices.Index(vector.Indices, tokenIndex(token))
+		if i < 0 {
+			return 0
+		}
+		return vector.Values[i]
+	}
+	tests := []struct {
+		name  string
+		text  string
+		avg   float64
+		token string
+		want  float32
+	}{
+		{"single term at average length", "refund", 1, "refund", 1},
+		{"repeated term saturates", "refund refund", 2, "refund", float32(2 * (BM25K1 + 1) / (2 + BM25K1))},
+		{"long document normalized down", "refund policy terms", 1.5, "refund", float32((BM25K1 + 1) / (1 + BM25K1*(1-BM25B+BM25B*3/1.5)))},
+		{"zero average uses document length", "refund", 0, "refund", 1},
+		{"stopwords dropped", "the refund", 1, "the", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vector := EncodeDocument(tt.text, tt.avg)
+			if got := weight(vector, tt.token); got != tt.want {
+				t.Fatalf("weight(%s) = %v, want %v", tt.token, got, tt.want)
+			}
+			if !slices.IsSorted(vector.Indices) || len(vector.Indices) != len(vector.Values) {
+				t.Fatalf("malformed vector %+v", vector)
+			}
+		})
+	}
+}
+
+func sortedCopy(values []uint32) []uint32 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted
+}
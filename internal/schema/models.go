@@ -1,11 +1,27 @@
 package schema
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
+const (
+	ModeCustomerSupport = "customer_support"
+	ModeStudentAnalysis = "student_analysis"
+)
+
+func IsSupportedMode(mode string) bool {
+	switch mode {
+	case ModeCustomerSupport, ModeStudentAnalysis:
+		return true
+	default:
+		return false
+	}
+}
+
 type UserRequest struct {
 	RequestID string `json:"request_id"`
 	TraceID   string `json:"trace_id"`
@@ -13,22 +29,94 @@ type UserRequest struct {
 	Message   string `json:"message"`
 	StudentID int    `json:"student_id"`
 	Term      string `json:"term"`
+	FromTerm  string `json:"from_term,omitempty"`
+	ToTerm    string `json:"to_term,omitempty"`
+
+	Filters *RetrievalFilters `json:"filters,omitempty"`
+}
+
+type RetrievalFilters struct {
+	Product  string `json:"product,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Language string `json:"language,omitempty"`
+	Audience string `json:"audience,omitempty"`
+}
+
+var RetrievalFilterKeys = []string{"product", "version", "language", "audience"}
+
+func (f RetrievalFilters) Fields() map[string]string {
+	fields := make(map[string]string, len(RetrievalFilterKeys))
+	for i, value := range []string{f.Product, f.Version, f.Language, f.Audience} {
+		if value = strings.TrimSpace(value); value != "" {
+			fields[RetrievalFilterKeys[i]] = value
+		}
+	}
+	return fields
 }
 
 func (req UserRequest) Validate() error {
+	var verr ValidationError
 	if strings.TrimSpace(req.RequestID) == "" {
-		return errors.New("request_id is required")
+		verr.Add("request_id", "is required")
 	}
 	if strings.TrimSpace(req.TraceID) == "" {
-		return errors.New("trace_id is required")
+		verr.Add("trace_id", "is required")
 	}
-	if strings.TrimSpace(req.Mode) == "" {
-		return errors.New("mode is required")
+	mode := strings.ToLower(strings.TrimSpace(req.Mode))
+	switch {
+	case mode == "":
+		verr.Add("mode", "is required")
+	case !IsSupportedMode(mode):
+		verr.Add("mode", fmt.Sprintf("must be %s or %s", ModeCustomerSupport, ModeStudentAnalysis))
 	}
 	if strings.TrimSpace(req.Message) == "" {
-		return errors.New("message is required")
+		verr.Add("message", "is required")
 	}
-	return nil
+	if req.StudentID < 0 {
+		verr.Add("student_id", "must be positive")
+	} else if mode == ModeStudentAnalysis && req.StudentID == 0 {
+		verr.Add("student_id", "is required for "+ModeStudentAnalysis)
+	}
+	if from, to := strings.TrimSpace(req.FromTerm), strings.TrimSpace(req.ToTerm); from != "" && to != "" && from > to {
+		verr.Add("from_term", "must not be after to_term")
+	}
+	if req.Filters != nil {
+		fields := req.Filters.Fields()
+		for _, key := range RetrievalFilterKeys {
+			if len(fields[key]) > 128 {
+				verr.Add("filters."+key, "must be at most 128 characters")
+			}
+		}
+	}
+	return verr.Err()
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		parts = append(parts, field.Field+" "+field.Message)
+	}
+	return strings.Join(parts, "; ")
 }
 
 type NormalizedRequest struct {
@@ -38,6 +126,28 @@ type NormalizedRequest struct {
 	Message   string
 	StudentID int
 	Term      string
+	FromTerm  string
+	ToTerm    string
+	Filters   map[string]string
+	Queries   []SearchQuery
+}
+
+const (
+	QueryRewrite      = "rewrite"
+	QueryVariant      = "variant"
+	QueryHypothetical = "hypothetical"
+)
+
+type SearchQuery struct {
+	Text string
+	Kind string
+}
+
+func (req NormalizedRequest) SearchQueries() []SearchQuery {
+	if len(req.Queries) == 0 {
+		return []SearchQuery{{Text: req.Message}}
+	}
+	return req.Queries
 }
 
 func (req NormalizedRequest) Validate() error {
@@ -53,9 +163,23 @@ func (req NormalizedRequest) Validate() error {
 	return nil
 }
 
+type Passage struct {
+	ID         string             `json:"id"`
+	Score      float64            `json:"score"`
+	Text       string             `json:"text"`
+	Title      string             `json:"title,omitempty"`
+	Source     string             `json:"source,omitempty"`
+	Section    string             `json:"section,omitempty"`
+	URL        string             `json:"url,omitempty"`
+	ChunkIndex *int               `json:"chunk_index,omitempty"`
+	Metadata   map[string]any     `json:"metadata,omitempty"`
+	Scores     map[string]float64 `json:"scores,omitempty"`
+	Vector     []float32          `json:"-"`
+}
+
 type RAGResult struct {
 	Query     string
-	Passages  []string
+	Passages  []Passage
 	Embedding []float32
 }
 
@@ -68,7 +192,7 @@ func (res RAGResult) Validate() error {
 
 type RAGContext struct {
 	Request   NormalizedRequest
-	Passages  []string
+	Passages  []Passage
 	Embedding []float32
 }
 
@@ -96,8 +220,12 @@ func (res MCPResult) Validate() error {
 
 type MCPContext struct {
 	Request  NormalizedRequest
-	Passages []string
+	Passages []Passage
 	Tool     MCPResult
+	History  map[string]any
+	Stats    []GroupStats
+	Trend    *TrendData
+	Profile  *ScoreProfile
 }
 
 func (ctx MCPContext) Validate() error {
@@ -133,11 +261,16 @@ func (req LLMRequest) Validate() error {
 }
 
 type LLMResponse struct {
-	Content string
+	Mode      string
+	Escalated bool
+	Content   string
+	Passages  []Passage
+	Trend     *TrendData
+	Profile   *ScoreProfile
 }
 
 func (res LLMResponse) Validate() error {
-	if strings.TrimSpace(res.Content) == "" {
+	if !res.Escalated && strings.TrimSpace(res.Content) == "" {
 		return errors.New("content is required")
 	}
 	return nil
@@ -159,11 +292,75 @@ func (tool ToolDefinition) Validate() error {
 	return nil
 }
 
+func IntArgument(args map[string]any, key string) (int, bool) {
+	return IntValue(args[key])
+}
+
+func IntValue(v any) (int, bool) {
+	switch value := v.(type) {
+	case int:
+		return value, true
+	case int64:
+		return int(value), true
+	case float64:
+		if value != float64(int(value)) {
+			return 0, false
+		}
+		return int(value), true
+	case json.Number:
+		parsed, err := strconv.Atoi(value.String())
+		return parsed, err == nil
+	case string:
+		parsed, err := strconv.Atoi(value)
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func FloatValue(v any) (float64, bool) {
+	switch value := v.(type) {
+	case float64:
+		return value, true
+	case float32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case json.Number:
+		parsed, err := value.Float64()
+		return parsed, err == nil
+	case string:
+		parsed, err := strconv.ParseFloat(value, 64)
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
+
 type StudentAnalysis struct {
+	RequestID       string                `json:"request_id,omitempty"`
+	TraceID         string                `json:"trace_id,omitempty"`
+	Mode            string                `json:"mode,omitempty"`
+	Escalated       bool                  `json:"escalated,omitempty"`
 	Summary         string                `json:"summary"`
 	Analysis        StudentAnalysisDetail `json:"analysis"`
 	Recommendations []Recommendation      `json:"recommendations"`
 	DataSnapshot    DataSnapshot          `json:"data_snapshot"`
+	TrendData       *TrendData            `json:"trend_data,omitempty"`
+	Profile         *ScoreProfile         `json:"profile,omitempty"`
+	Passages        []Passage             `json:"passages,omitempty"`
+	Citations       []Citation            `json:"citations,omitempty"`
+}
+
+type Citation struct {
+	Index     int    `json:"index"`
+	PassageID string `json:"passage_id"`
+	Title     string `json:"title,omitempty"`
+	Source    string `json:"source,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Snippet   string `json:"snippet"`
 }
 
 type StudentAnalysisDetail struct {
@@ -172,25 +369,122 @@ type StudentAnalysisDetail struct {
 	Trend      string   `json:"trend"`
 }
 
+const (
+	TrendImproving    = "improving"
+	TrendDeclining    = "declining"
+	TrendStable       = "stable"
+	TrendInsufficient = "insufficient_data"
+)
+
+type TrendData struct {
+	Terms     []string       `json:"terms"`
+	Direction string         `json:"direction"`
+	Subjects  []SubjectTrend `json:"subjects"`
+}
+
+func (t TrendData) Sufficient() bool {
+	return len(t.Terms) >= 2
+}
+
+type SubjectTrend struct {
+	Subject   string       `json:"subject"`
+	Direction string       `json:"direction"`
+	Scores    []TermScore  `json:"scores"`
+	Changes   []TermChange `json:"changes"`
+	Delta     float64      `json:"delta"`
+	Slope     float64      `json:"slope"`
+}
+
+type TermScore struct {
+	Term  string  `json:"term"`
+	Score float64 `json:"score"`
+}
+
+type TermChange struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Change float64 `json:"change"`
+}
+
+const (
+	LevelStrength = "strength"
+	LevelAverage  = "average"
+	LevelWeakness = "weakness"
+)
+
+type ScoreThresholds struct {
+	Pass     float64 `json:"pass"`
+	Strength float64 `json:"strength"`
+	Weakness float64 `json:"weakness"`
+}
+
+type ScoreProfile struct {
+	Mean       float64          `json:"mean"`
+	Thresholds ScoreThresholds  `json:"thresholds"`
+	Subjects   []SubjectProfile `json:"subjects"`
+	Strengths  []string         `json:"strengths"`
+	Weaknesses []string         `json:"weaknesses"`
+}
+
+type SubjectProfile struct {
+	Subject     string       `json:"subject"`
+	Score       float64      `json:"score"`
+	Rank        int          `json:"rank"`
+	Passed      bool         `json:"passed"`
+	Level       string       `json:"level"`
+	Comparisons []Comparison `json:"comparisons"`
+}
+
+type Comparison struct {
+	Scope   string `json:"scope"`
+	GroupID string `json:"group_id"`
+	SubjectStats
+}
+
+const (
+	ScopeClass = "class"
+	ScopeGrade = "grade"
+)
+
+type GroupStats struct {
+	Scope    string         `json:"scope"`
+	GroupID  string         `json:"group_id"`
+	Term     string         `json:"term"`
+	Subjects []SubjectStats `json:"subjects"`
+}
+
+type SubjectStats struct {
+	Subject      string   `json:"subject"`
+	Count        int      `json:"count"`
+	Mean         float64  `json:"mean"`
+	Median       float64  `json:"median"`
+	StdDev       float64  `json:"std_dev"`
+	StudentScore *float64 `json:"student_score,omitempty"`
+	Percentile   *float64 `json:"percentile,omitempty"`
+}
+
 type Recommendation struct {
 	Action  string `json:"action"`
 	Example string `json:"example"`
 }
 
 type DataSnapshot struct {
-	Math    int `json:"math"`
-	English int `json:"english"`
-	Physics int `json:"physics"`
+	Math    *int `json:"math,omitempty"`
+	English *int `json:"english,omitempty"`
+	Physics *int `json:"physics,omitempty"`
 }
 
 func (resp StudentAnalysis) Validate() error {
 	if strings.TrimSpace(resp.Summary) == "" {
 		return errors.New("summary is required")
 	}
-	if len(resp.Analysis.Strengths) == 0 {
+	if resp.Escalated {
+		return validateRecommendations(resp.Recommendations)
+	}
+	if resp.Analysis.Strengths == nil {
 		return errors.New("analysis.strengths is required")
 	}
-	if len(resp.Analysis.Weaknesses) == 0 {
+	if resp.Analysis.Weaknesses == nil {
 		return errors.New("analysis.weaknesses is required")
 	}
 	if strings.TrimSpace(resp.Analysis.Trend) == "" {
@@ -199,13 +493,94 @@ func (resp StudentAnalysis) Validate() error {
 	if len(resp.Recommendations) == 0 {
 		return errors.New("recommendations is required")
 	}
-	for i, rec := range resp.Recommendations {
+	if err := validateRecommendations(resp.Recommendations); err != nil {
+		return err
+	}
+	if resp.Profile != nil {
+		return nil
+	}
+	if resp.DataSnapshot.Math == nil || resp.DataSnapshot.English == nil || resp.DataSnapshot.Physics == nil {
+		return errors.New("data_snapshot requires math, english, physics")
+	}
+	return nil
+}
+
+func validateRecommendations(recommendations []Recommendation) error {
+	for i, rec := range recommendations {
 		if strings.TrimSpace(rec.Action) == "" || strings.TrimSpace(rec.Example) == "" {
 			return fmt.Errorf("recommendations[%d] must include action and example", i)
 		}
 	}
-	if resp.DataSnapshot.Math == 0 || resp.DataSnapshot.English == 0 || resp.DataSnapshot.Physics == 0 {
-		return errors.New("data_snapshot requires math, english, physics")
-	}
 	return nil
 }
+
+const MaxBatchStudents = 500
+
+type BatchRequest struct {
+	UserRequest
+	ClassID    int   `json:"class_id,omitempty"`
+	StudentIDs []int `json:"student_ids,omitempty"`
+}
+
+func (req *BatchRequest) ApplyDefaults() {
+	if strings.TrimSpace(req.Mode) == "" {
+		req.Mode = ModeStudentAnalysis
+	}
+}
+
+func (req BatchRequest) Validate() error {
+	var verr ValidationError
+	if strings.TrimSpace(req.RequestID) == "" {
+		verr.Add("request_id", "is required")
+	}
+	if strings.TrimSpace(req.TraceID) == "" {
+		verr.Add("trace_id", "is required")
+	}
+	if mode := strings.ToLower(strings.TrimSpace(req.Mode)); mode != "" && mode != ModeStudentAnalysis {
+		verr.Add("mode", "must be "+ModeStudentAnalysis)
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		verr.Add("message", "is required")
+	}
+	if req.StudentID != 0 {
+		verr.Add("student_id", "is not supported for batches, use student_ids")
+	}
+	switch {
+	case req.ClassID < 0:
+		verr.Add("class_id", "must be positive")
+	case req.ClassID == 0 && len(req.StudentIDs) == 0:
+		verr.Add("class_id", "class_id or student_ids is required")
+	case req.ClassID != 0 && len(req.StudentIDs) > 0:
+		verr.Add("student_ids", "cannot be combined with class_id")
+	}
+	if len(req.StudentIDs) > MaxBatchStudents {
+		verr.Add("student_ids", fmt.Sprintf("must contain at most %d entries", MaxBatchStudents))
+	}
+	for i, studentID := range req.StudentIDs {
+		if studentID <= 0 {
+			verr.Add(fmt.Sprintf("student_ids[%d]", i), "must be positive")
+		}
+	}
+	return verr.Err()
+}
+
+const (
+	BatchItemSucceeded = "succeeded"
+	BatchItemFailed    = "failed"
+)
+
+type BatchItem struct {
+	StudentID int              `json:"student_id"`
+	Status    string           `json:"status"`
+	Result    *StudentAnalysis `json:"result,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	RequestID string      `json:"request_id"`
+	TraceID   string      `json:"trace_id"`
+	ClassID   int         `json:"class_id,omitempty"`
+	Succeeded int         `json:"succeeded"`
+	Failed    int         `json:"failed"`
+	Items     []BatchItem `json:"items"`
+}
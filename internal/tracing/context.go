@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type TraceID [16]byte
+
+type SpanID [8]byte
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+const FlagSampled byte = 0x01
+
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, errors.New("traceparent must have four fields")
+	}
+	if len(parts[0]) != 2 || !lowerHex(parts[0]) || parts[0] == "ff" {
+		return SpanContext{}, errors.New("traceparent version must be 2 lowercase hex characters other than ff")
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, errors.New("traceparent version 00 must have four fields")
+	}
+	var sc SpanContext
+	traceID, err := ParseTraceID(parts[1])
+	if err != nil {
+		return SpanContext{}, err
+	}
+	sc.TraceID = traceID
+	if len(parts[2]) != 16 || !lowerHex(parts[2]) {
+		return SpanContext{}, errors.New("traceparent parent-id must be 16 lowercase hex characters")
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil || !sc.SpanID.IsValid() {
+		return SpanContext{}, errors.New("traceparent parent-id is invalid")
+	}
+	var flags [1]byte
+	if len(parts[3]) != 2 || !lowerHex(parts[3]) {
+		return SpanContext{}, errors.New("traceparent flags must be 2 lowercase hex characters")
+	}
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return SpanContext{}, errors.New("traceparent flags are invalid")
+	}
+	sc.Flags = flags[0]
+	return sc, nil
+}
+
+func lowerHex(value string) bool {
+	for _, c := range value {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func ParseTraceID(value string) (TraceID, error) {
+	var id TraceID
+	if len(value) != 32 || strings.ToLower(value) != value {
+		return TraceID{}, errors.New("trace-id must be 32 lowercase hex characters")
+	}
+	if _, err := hex.Decode(id[:], []byte(value)); err != nil || !id.IsValid() {
+		return TraceID{}, errors.New("trace-id is invalid")
+	}
+	return id, nil
+}
+
+func NewTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func NewSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
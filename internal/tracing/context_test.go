@@ -0,0 +1,63 @@
+package tracing
+
+import "testing"
+
+func TestParseTraceparent(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	tests := []struct {
+		name    string
+		value   string
+		flags   byte
+		wantErr bool
+	}{
+		{"sampled", "00-" + traceID + "-" + spanID + "-01", FlagSampled, false},
+		{"not sampled", "00-" + traceID + "-" + spanID + "-00", 0, false},
+		{"surrounding space", " 00-" + traceID + "-" + spanID + "-01 ", FlagSampled, false},
+		{"future version with extra field", "cc-" + traceID + "-" + spanID + "-01-extra", FlagSampled, false},
+		{"empty", "", 0, true},
+		{"too few fields", "00-" + traceID + "-" + spanID, 0, true},
+		{"version 00 with extra field", "00-" + traceID + "-" + spanID + "-01-extra", 0, true},
+		{"version ff", "ff-" + traceID + "-" + spanID + "-01", 0, true},
+		{"non-hex version", "zz-" + traceID + "-" + spanID + "-01", 0, true},
+		{"uppercase version", "0A-" + traceID + "-" + spanID + "-01", 0, true},
+		{"short trace id", "00-4bf92f35-" + spanID + "-01", 0, true},
+		{"uppercase trace id", "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + spanID + "-01", 0, true},
+		{"zero trace id", "00-00000000000000000000000000000000-" + spanID + "-01", 0, true},
+		{"short span id", "00-" + traceID + "-00f067aa-01", 0, true},
+		{"uppercase span id", "00-" + traceID + "-00F067AA0BA902B7-01", 0, true},
+		{"zero span id", "00-" + traceID + "-0000000000000000-01", 0, true},
+		{"bad flags", "00-" + traceID + "-" + spanID + "-zz", 0, true},
+		{"uppercase flags", "00-" + traceID + "-" + spanID + "-0A", 0, true},
+		{"long flags", "00-" + traceID + "-" + spanID + "-001", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceparent(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTraceparent(%q) = %+v, want error", tt.value, sc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTraceparent(%q) error = %v", tt.value, err)
+			}
+			if sc.TraceID.String() != traceID || sc.SpanID.String() != spanID || sc.Flags != tt.flags {
+				t.Fatalf("ParseTraceparent(%q) = %s", tt.value, sc.Traceparent())
+			}
+		})
+	}
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	for range 10 {
+		sc := SpanContext{TraceID: NewTraceID(), SpanID: NewSpanID(), Flags: FlagSampled}
+		parsed, err := ParseTraceparent(sc.Traceparent())
+		if err != nil || parsed != sc {
+			t.Fatalf("round trip of %s = %+v, %v", sc.Traceparent(), parsed, err)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type otlpPayload struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+func EncodeOTLP(serviceName string, spans []SpanData) ([]byte, error) {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		item := otlpSpan{
+			TraceID:           span.Context.TraceID.String(),
+			SpanID:            span.Context.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            otlpStatus{Code: 1},
+		}
+		if span.ParentID.IsValid() {
+			item.ParentSpanID = span.ParentID.String()
+		}
+		if span.Err != "" {
+			item.Status = otlpStatus{Code: 2, Message: span.Err}
+		}
+		encoded = append(encoded, item)
+	}
+	payload := otlpPayload{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes(map[string]any{"service.name": serviceName})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "omnibase"}, Spans: encoded}},
+	}}}
+	return json.Marshal(payload)
+}
+
+func otlpAttributes(attrs map[string]any) []otlpKeyValue {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]otlpKeyValue, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, otlpKeyValue{Key: key, Value: otlpValue(attrs[key])})
+	}
+	return values
+}
+
+func otlpValue(value any) map[string]any {
+	switch v := value.(type) {
+	case string:
+		return map[string]any{"stringValue": v}
+	case bool:
+		return map[string]any{"boolValue": v}
+	case int:
+		return map[string]any{"intValue": strconv.Itoa(v)}
+	case int64:
+		return map[string]any{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		return map[string]any{"doubleValue": v}
+	case float32:
+		return map[string]any{"doubleValue": float64(v)}
+	default:
+		return map[string]any{"stringValue": fmt.Sprint(v)}
+	}
+}
+
+type FileExporter struct {
+	serviceName string
+	path        string
+	mu          sync.Mutex
+}
+
+func NewFileExporter(serviceName, path string) *FileExporter {
+	return &FileExporter{serviceName: serviceName, path: path}
+}
+
+func (e *FileExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := EncodeOTLP(e.serviceName, spans)
+	if err != nil {
+		return fmt.Errorf("encode spans: %w", err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	file, err := os.OpenFile(e.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open trace file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(body, '\n')); err != nil {
+		return fmt.Errorf("write trace file: %w", err)
+	}
+	return nil
+}
+
+type HTTPExporter struct {
+	serviceName string
+	endpoint    string
+	client      *http.Client
+}
+
+func NewHTTPExporter(serviceName, endpoint string) *HTTPExporter {
+	return &HTTPExporter{serviceName: serviceName, endpoint: endpoint, client: &http.Client{}}
+}
+
+func (e *HTTPExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := EncodeOTLP(e.serviceName, spans)
+	if err != nil {
+		return fmt.Errorf("encode spans: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create export request: %w", err)
+	}
+	req.Header.Set("content-type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send export request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("trace export failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type Exporters []Exporter
+
+func (e Exporters) Export(ctx context.Context, spans []SpanData) error {
+	var errs []error
+	for _, exporter := range e {
+		if err := exporter.Export(ctx, spans); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+type SpanData struct {
+	Name       string
+	Kind       SpanKind
+	Context    SpanContext
+	ParentID   SpanID
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]any
+	Err        string
+}
+
+type Span struct {
+	tracer *Tracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.Context
+}
+
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes[key] = value
+}
+
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err.Error()
+}
+
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+	s.tracer.export(data)
+}
+
+func (s *Span) Finish(err error) {
+	s.RecordError(err)
+	s.End()
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+type tracerKey struct{}
+
+func ContextWithTracer(ctx context.Context, tracer *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, tracer)
+}
+
+func ContextWithRemote(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.Context()
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	tracer, _ := ctx.Value(tracerKey{}).(*Tracer)
+	if tracer == nil {
+		return ctx, nil
+	}
+	return tracer.Start(ctx, name, kind)
+}
+
+func Inject(ctx context.Context, header http.Header) {
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		header.Set("traceparent", sc.Traceparent())
+	}
+}
+
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if sc := SpanContextFromContext(req.Context()); sc.IsValid() {
+		req = req.Clone(req.Context())
+		req.Header.Set("traceparent", sc.Traceparent())
+	}
+	return t.Base.RoundTrip(req)
+}
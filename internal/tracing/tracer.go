@@ -0,0 +1,144 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+type TracerConfig struct {
+	ServiceName   string
+	BatchSize     int
+	FlushInterval time.Duration
+	QueueSize     int
+}
+
+type Tracer struct {
+	cfg      TracerConfig
+	exporter Exporter
+	logger   *slog.Logger
+
+	queue chan SpanData
+	done  chan struct{}
+	once  sync.Once
+	wg    sync.WaitGroup
+}
+
+func NewTracer(cfg TracerConfig, exporter Exporter, logger *slog.Logger) *Tracer {
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "omnibase"
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 256
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 4096
+	}
+	t := &Tracer{cfg: cfg, exporter: exporter, logger: logger, done: make(chan struct{})}
+	if exporter != nil {
+		t.queue = make(chan SpanData, cfg.QueueSize)
+		t.wg.Add(1)
+		go t.run()
+	}
+	return t
+}
+
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{TraceID: parent.TraceID, SpanID: NewSpanID(), Flags: FlagSampled}
+	if !parent.TraceID.IsValid() {
+		sc.TraceID = NewTraceID()
+	} else {
+		sc.Flags = parent.Flags | FlagSampled
+	}
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:       name,
+			Kind:       kind,
+			Context:    sc,
+			ParentID:   parent.SpanID,
+			Start:      time.Now(),
+			Attributes: make(map[string]any),
+		},
+	}
+	ctx = ContextWithTracer(ctx, t)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t.queue == nil {
+		return nil
+	}
+	t.once.Do(func() { close(t.done) })
+	finished := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Tracer) export(data SpanData) {
+	if t == nil || t.queue == nil {
+		return
+	}
+	select {
+	case <-t.done:
+	case t.queue <- data:
+	default:
+		t.logger.Warn("trace queue full, dropping span", "span", data.Name)
+	}
+}
+
+func (t *Tracer) run() {
+	defer t.wg.Done()
+	ticker := time.NewTicker(t.cfg.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]SpanData, 0, t.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := t.exporter.Export(ctx, batch); err != nil {
+			t.logger.Error("trace export failed", "error", err.Error(), "span_count", len(batch))
+		}
+		batch = make([]SpanData, 0, t.cfg.BatchSize)
+	}
+	for {
+		select {
+		case data := <-t.queue:
+			batch = append(batch, data)
+			if len(batch) >= t.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-t.done:
+			for {
+				select {
+				case data := <-t.queue:
+					batch = append(batch, data)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
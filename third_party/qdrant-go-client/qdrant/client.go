@@ -6,8 +6,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 )
 
+type StatusError struct {
+	Op         string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s failed: status %d", e.Op, e.StatusCode)
+}
+
+func (e *StatusError) HTTPStatus() int { return e.StatusCode }
+
 type Client struct {
 	BaseURL string
 	APIKey  string
@@ -18,11 +30,88 @@ func NewClient(baseURL, apiKey string) *Client {
 	return &Client{BaseURL: baseURL, APIKey: apiKey, client: &http.Client{}}
 }
 
+func (c *Client) WithHTTPClient(client *http.Client) *Client {
+	c.client = client
+	return c
+}
+
 type SearchPointsRequest struct {
-	Collection  string
-	Vector      []float32
-	Limit       uint32
-	WithPayload bool
+	Collection   string
+	Vector       []float32
+	VectorName   string
+	SparseVector *SparseVector
+	Limit        uint32
+	WithPayload  bool
+	WithVector   bool
+	WithVectors  []string
+	Filter       *Filter
+}
+
+type SparseVector struct {
+	Indices []uint32  `json:"indices"`
+	Values  []float32 `json:"values"`
+}
+
+type namedVector struct {
+	Name   string `json:"name"`
+	Vector any    `json:"vector"`
+}
+
+type Filter struct {
+	Must    []Condition `json:"must,omitempty"`
+	Should  []Condition `json:"should,omitempty"`
+	MustNot []Condition `json:"must_not,omitempty"`
+}
+
+type Condition struct {
+	Key     string    `json:"key,omitempty"`
+	Match   *Match    `json:"match,omitempty"`
+	Range   *Range    `json:"range,omitempty"`
+	IsEmpty *IsEmpty  `json:"is_empty,omitempty"`
+	HasID   []PointID `json:"has_id,omitempty"`
+	*Filter
+}
+
+type Match struct {
+	Value  any    `json:"value,omitempty"`
+	Any    []any  `json:"any,omitempty"`
+	Except []any  `json:"except,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+type Range struct {
+	GT  *float64 `json:"gt,omitempty"`
+	GTE *float64 `json:"gte,omitempty"`
+	LT  *float64 `json:"lt,omitempty"`
+	LTE *float64 `json:"lte,omitempty"`
+}
+
+type IsEmpty struct {
+	Key string `json:"key"`
+}
+
+func NewMatch(key string, value any) Condition {
+	return Condition{Key: key, Match: &Match{Value: value}}
+}
+
+func NewMatchAny(key string, values ...any) Condition {
+	return Condition{Key: key, Match: &Match{Any: values}}
+}
+
+func NewRange(key string, r Range) Condition {
+	return Condition{Key: key, Range: &r}
+}
+
+func NewIsEmpty(key string) Condition {
+	return Condition{IsEmpty: &IsEmpty{Key: key}}
+}
+
+func NewHasID(ids ...PointID) Condition {
+	return Condition{HasID: ids}
+}
+
+func NewFilterCondition(filter Filter) Condition {
+	return Condition{Filter: &filter}
 }
 
 type SearchPointsResponse struct {
@@ -30,16 +119,104 @@ type SearchPointsResponse struct {
 }
 
 type ScoredPoint struct {
+	ID      PointID        `json:"id"`
+	Version uint64         `json:"version"`
+	Score   float32        `json:"score"`
 	Payload map[string]any `json:"payload"`
+	Vector  Vectors        `json:"vector"`
+}
+
+type Vectors struct {
+	Default []float32
+	Named   map[string][]float32
+}
+
+func (v Vectors) Get(name string) []float32 {
+	if vector, ok := v.Named[name]; ok {
+		return vector
+	}
+	if name == "" {
+		return v.Default
+	}
+	return nil
+}
+
+func (v *Vectors) UnmarshalJSON(data []byte) error {
+	*v = Vectors{}
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, &v.Default)
+	}
+	var named map[string]json.RawMessage
+	if err := json.Unmarshal(data, &named); err != nil {
+		return err
+	}
+	for name, raw := range named {
+		var dense []float32
+		if len(raw) == 0 || raw[0] != '[' {
+			continue
+		}
+		if err := json.Unmarshal(raw, &dense); err != nil {
+			return err
+		}
+		if v.Named == nil {
+			v.Named = make(map[string][]float32)
+		}
+		v.Named[name] = dense
+	}
+	return nil
+}
+
+type PointID struct {
+	Num  uint64
+	UUID string
+}
+
+func (id PointID) String() string {
+	if id.UUID != "" {
+		return id.UUID
+	}
+	return strconv.FormatUint(id.Num, 10)
+}
+
+func (id PointID) MarshalJSON() ([]byte, error) {
+	if id.UUID != "" {
+		return json.Marshal(id.UUID)
+	}
+	return json.Marshal(id.Num)
+}
+
+func (id *PointID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		id.Num = 0
+		return json.Unmarshal(data, &id.UUID)
+	}
+	id.UUID = ""
+	return json.Unmarshal(data, &id.Num)
 }
 
 func (c *Client) SearchPoints(ctx context.Context, req SearchPointsRequest) (SearchPointsResponse, error) {
 	endpoint := fmt.Sprintf("%s/collections/%s/points/search", c.BaseURL, req.Collection)
+	var vector any = req.Vector
+	switch {
+	case req.SparseVector != nil:
+		vector = namedVector{Name: req.VectorName, Vector: req.SparseVector}
+	case req.VectorName != "":
+		vector = namedVector{Name: req.VectorName, Vector: req.Vector}
+	}
 	payload := map[string]any{
-		"vector":       req.Vector,
+		"vector":       vector,
 		"limit":        req.Limit,
 		"with_payload": req.WithPayload,
 	}
+	switch {
+	case len(req.WithVectors) > 0:
+		payload["with_vector"] = req.WithVectors
+	case req.WithVector:
+		payload["with_vector"] = true
+	}
+	if req.Filter != nil {
+		payload["filter"] = req.Filter
+	}
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return SearchPointsResponse{}, fmt.Errorf("encode search request: %w", err)
@@ -60,7 +237,7 @@ func (c *Client) SearchPoints(ctx context.Context, req SearchPointsRequest) (Sea
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
-		return SearchPointsResponse{}, fmt.Errorf("search failed: status %d", resp.StatusCode)
+		return SearchPointsResponse{}, &StatusError{Op: "search", StatusCode: resp.StatusCode}
 	}
 
 	var decoded SearchPointsResponse
@@ -69,3 +246,83 @@ func (c *Client) SearchPoints(ctx context.Context, req SearchPointsRequest) (Sea
 	}
 	return decoded, nil
 }
+
+type CollectionInfo struct {
+	Config CollectionConfig `json:"config"`
+}
+
+type CollectionConfig struct {
+	Params CollectionParams `json:"params"`
+}
+
+type CollectionParams struct {
+	Vectors       VectorsConfig                 `json:"vectors"`
+	SparseVectors map[string]SparseVectorParams `json:"sparse_vectors"`
+}
+
+type SparseVectorParams struct {
+	Modifier string `json:"modifier"`
+}
+
+type VectorParams struct {
+	Size     uint64 `json:"size"`
+	Distance string `json:"distance"`
+}
+
+type VectorsConfig struct {
+	Default *VectorParams
+	Named   map[string]VectorParams
+}
+
+func (v VectorsConfig) Get(name string) (VectorParams, bool) {
+	if params, ok := v.Named[name]; ok {
+		return params, true
+	}
+	if name == "" && v.Default != nil {
+		return *v.Default, true
+	}
+	return VectorParams{}, false
+}
+
+func (v *VectorsConfig) UnmarshalJSON(data []byte) error {
+	*v = VectorsConfig{}
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return err
+	}
+	if _, ok := probe["size"]; ok {
+		v.Default = &VectorParams{}
+		return json.Unmarshal(data, v.Default)
+	}
+	return json.Unmarshal(data, &v.Named)
+}
+
+type GetCollectionResponse struct {
+	Result CollectionInfo `json:"result"`
+}
+
+func (c *Client) GetCollection(ctx context.Context, collection string) (CollectionInfo, error) {
+	endpoint := fmt.Sprintf("%s/collections/%s", c.BaseURL, collection)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return CollectionInfo{}, fmt.Errorf("create collection request: %w", err)
+	}
+	if c.APIKey != "" {
+		httpReq.Header.Set("api-key", c.APIKey)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return CollectionInfo{}, fmt.Errorf("send collection request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return CollectionInfo{}, &StatusError{Op: "get collection", StatusCode: resp.StatusCode}
+	}
+
+	var decoded GetCollectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return CollectionInfo{}, fmt.Errorf("decode collection response: %w", err)
+	}
+	return decoded.Result, nil
+}